@@ -1,9 +1,12 @@
 package form3_task
 
 import (
+	"fmt"
+	"time"
+
 	"github.com/google/uuid"
 	"github.com/petegabriel/form3_task/data"
-	"log"
+	"github.com/petegabriel/form3_task/iban"
 )
 
 //Classification of account. Can be one of 'Personal' or 'Business'.
@@ -14,6 +17,45 @@ const (
 	Business Classification = "Business"
 )
 
+//AccountStatus is the state of an account in its lifecycle.
+type AccountStatus string
+
+const (
+	StatusPending   AccountStatus = "pending"
+	StatusConfirmed AccountStatus = "confirmed"
+	StatusFailed    AccountStatus = "failed"
+	StatusClosed    AccountStatus = "closed"
+)
+
+//UserDefinedData is a key/value pair stored with the account.
+type UserDefinedData = data.UserDefinedData
+
+//PrivateIdentification identifies the person holding a personal account.
+//BirthDate is in YYYY-MM-DD format and the countries are ISO 3166-1 alpha-2 codes.
+type PrivateIdentification = data.PrivateIdentification
+
+//OrganisationIdentification identifies the organisation holding a business account.
+type OrganisationIdentification = data.OrganisationIdentification
+
+//Actor is a person acting on behalf of the organisation holding a business account.
+type Actor = data.Actor
+
+//Relationships links the account to other resources by their ids.
+type Relationships struct {
+
+	//MasterAccount holds the id of the master account, if any.
+	MasterAccount []uuid.UUID
+
+	//AccountEvents holds the ids of the events of the account.
+	AccountEvents []uuid.UUID
+}
+
+//Types of the resources linked to an account.
+const (
+	masterAccountType = "accounts"
+	accountEventType  = "account_events"
+)
+
 //Account represents a bank account that is registered with Form3 fake account api.
 type Account struct {
 
@@ -21,10 +63,12 @@ type Account struct {
 	Id uuid.UUID
 
 	//CreatedOn represents the time and date on which the resource was created.
-	CreatedOn string
+	//Zero until the account is created.
+	CreatedOn time.Time
 
 	//ModifiedOn represents the time and date on which the resource was last modified.
-	ModifiedOn string
+	//Zero until the account is created.
+	ModifiedOn time.Time
 
 	//OrganisationId of the account.
 	OrganisationId uuid.UUID
@@ -33,10 +77,10 @@ type Account struct {
 	Version int
 
 	//Country is an ISO code used to identify the domicile of the account.
-	Country string
+	Country Country
 
 	//BaseCurrency is an ISO code used to identify the base currency of the account.
-	BaseCurrency string
+	BaseCurrency Currency
 
 	//AccountNumber identifies uniquely the account. Generated if not provided.
 	AccountNumber string
@@ -73,10 +117,55 @@ type Account struct {
 
 	//IsSwitched flag to indicate if the account has been switched away from this organisation.
 	IsSwitched bool
+
+	//Status of the account. Set by the account api when empty.
+	Status AccountStatus
+
+	//StatusReason explains the status (e.g. 'account-closed', 'invalid-account-name').
+	StatusReason string
+
+	//UserDefinedData holds key/value pairs stored with the account.
+	UserDefinedData []UserDefinedData
+
+	//ValidationType of the account (e.g. 'card').
+	ValidationType string
+
+	//ReferenceMask is the mask the payment references sent to the account must match.
+	ReferenceMask string
+
+	//AcceptanceQualifier of the account (e.g. 'same_day').
+	AcceptanceQualifier string
+
+	//ProcessingService in charge of the account.
+	ProcessingService string
+
+	//UserDefinedInformation is free text stored with the account.
+	UserDefinedInformation string
+
+	//CustomerId identifies the customer holding the account.
+	CustomerId string
+
+	//Title of the account holder (e.g. 'Ms').
+	Title string
+
+	//FirstName of the account holder.
+	FirstName string
+
+	//BankAccountName is the name of the account as known by the bank.
+	BankAccountName string
+
+	//PrivateIdentification identifies the holder of a personal account.
+	PrivateIdentification *PrivateIdentification
+
+	//OrganisationIdentification identifies the holder of a business account.
+	OrganisationIdentification *OrganisationIdentification
+
+	//Relationships links the account to other resources.
+	Relationships *Relationships
 }
 
 //NewAccount creates an instance of Account with default values assigned.
-func NewAccount(name []string, country string, id, orgId uuid.UUID) *Account {
+func NewAccount(name []string, country Country, id, orgId uuid.UUID) *Account {
 	//all other fields will be automatically initialised by their
 	//respective 'zero value'.
 	return &Account{
@@ -89,27 +178,38 @@ func NewAccount(name []string, country string, id, orgId uuid.UUID) *Account {
 }
 
 //NewAccountFromDto creates a new instance of Account based upon
-//an instance of AccountDto.
-func NewAccountFromDto(dto data.AccountDto) *Account {
+//an instance of AccountDto. Returns a *DecodeError if the dto holds
+//values which cannot be represented in an Account (e.g. a malformed id).
+func NewAccountFromDto(dto data.AccountDto) (*Account, error) {
 	name := dto.Data.Attributes.Name
-	ctry := dto.Data.Attributes.Country
+	ctry := Country(dto.Data.Attributes.Country)
 	id, oid := dto.Data.ID, dto.Data.OrganisationID
 
 	uid, err := uuid.Parse(id)
 	if err != nil {
-		log.Fatal(err)
+		return nil, &DecodeError{Field: "id", Value: id, Err: err}
 	}
 
 	ouid, err := uuid.Parse(oid)
 	if err != nil {
-		log.Fatal(err)
+		return nil, &DecodeError{Field: "organisation_id", Value: oid, Err: err}
+	}
+
+	created, err := data.ParseTimestamp(dto.Data.CreatedOn)
+	if err != nil {
+		return nil, &DecodeError{Field: "created_on", Value: dto.Data.CreatedOn, Err: err}
+	}
+
+	modified, err := data.ParseTimestamp(dto.Data.ModifiedOn)
+	if err != nil {
+		return nil, &DecodeError{Field: "modified_on", Value: dto.Data.ModifiedOn, Err: err}
 	}
 
 	acc := NewAccount(name, ctry, uid, ouid)
-	acc.CreatedOn = dto.Data.CreatedOn
-	acc.ModifiedOn = dto.Data.ModifiedOn
+	acc.CreatedOn = created
+	acc.ModifiedOn = modified
 	acc.Version = dto.Data.Version
-	acc.BaseCurrency = dto.Data.Attributes.BaseCurrency
+	acc.BaseCurrency = Currency(dto.Data.Attributes.BaseCurrency)
 	acc.AccountNumber = dto.Data.Attributes.AccountNumber
 	acc.BankId = dto.Data.Attributes.BankID
 	acc.BankIdCode = dto.Data.Attributes.BankIDCode
@@ -122,17 +222,71 @@ func NewAccountFromDto(dto data.AccountDto) *Account {
 	acc.IsAccountMatchingOptOut = dto.Data.Attributes.AccountMatchingOptOut
 	acc.SecondaryIdentification = dto.Data.Attributes.SecondaryIdentification
 	acc.IsSwitched = dto.Data.Attributes.Switched
-	return acc
+	acc.Status = AccountStatus(dto.Data.Attributes.Status)
+	acc.StatusReason = dto.Data.Attributes.StatusReason
+	acc.UserDefinedData = dto.Data.Attributes.UserDefinedData
+	acc.ValidationType = dto.Data.Attributes.ValidationType
+	acc.ReferenceMask = dto.Data.Attributes.ReferenceMask
+	acc.AcceptanceQualifier = dto.Data.Attributes.AcceptanceQualifier
+	acc.ProcessingService = dto.Data.Attributes.ProcessingService
+	acc.UserDefinedInformation = dto.Data.Attributes.UserDefinedInformation
+	acc.CustomerId = dto.Data.Attributes.CustomerID
+	acc.Title = dto.Data.Attributes.Title
+	acc.FirstName = dto.Data.Attributes.FirstName
+	acc.BankAccountName = dto.Data.Attributes.BankAccountName
+	acc.PrivateIdentification = dto.Data.Attributes.PrivateIdentification
+	acc.OrganisationIdentification = dto.Data.Attributes.OrganisationIdentification
+
+	if acc.Relationships, err = relationshipsFromDto(dto.Data.Relationships); err != nil {
+		return nil, err
+	}
+	return acc, nil
+}
 
+func relationshipsFromDto(rel *data.Relationships) (*Relationships, error) {
+	if rel == nil {
+		return nil, nil
+	}
+	ids := func(field string, rd *data.RelationshipData) ([]uuid.UUID, error) {
+		if rd == nil {
+			return nil, nil
+		}
+		parsed := make([]uuid.UUID, 0, len(rd.Data))
+		for _, link := range rd.Data {
+			id, err := uuid.Parse(link.ID)
+			if err != nil {
+				return nil, &DecodeError{Field: field, Value: link.ID, Err: err}
+			}
+			parsed = append(parsed, id)
+		}
+		return parsed, nil
+	}
+
+	var err error
+	r := &Relationships{}
+	if r.MasterAccount, err = ids("relationships.master_account", rel.MasterAccount); err != nil {
+		return nil, err
+	}
+	if r.AccountEvents, err = ids("relationships.account_events", rel.AccountEvents); err != nil {
+		return nil, err
+	}
+	return r, nil
 }
 
-//ToDto transforms an instance of Account into a new instance of AccountDto
-func (info *Account) ToDto() data.AccountDto {
-	dto := data.NewAccountDto(info.Id, info.OrganisationId, info.Country, info.Name)
-	dto.Data.CreatedOn = info.CreatedOn
-	dto.Data.ModifiedOn = info.ModifiedOn
+//ToDto transforms an instance of Account into a new instance of AccountDto.
+//Returns an error matching ErrInvalidID if the account has no id or organisation id.
+func (info *Account) ToDto() (data.AccountDto, error) {
+	if info.Id == uuid.Nil {
+		return data.AccountDto{}, fmt.Errorf("%w: account id is missing", ErrInvalidID)
+	}
+	if info.OrganisationId == uuid.Nil {
+		return data.AccountDto{}, fmt.Errorf("%w: organisation id is missing", ErrInvalidID)
+	}
+	dto := data.NewAccountDto(info.Id, info.OrganisationId, string(info.Country), info.Name)
+	dto.Data.CreatedOn = data.FormatTimestamp(info.CreatedOn)
+	dto.Data.ModifiedOn = data.FormatTimestamp(info.ModifiedOn)
 	dto.Data.Version = info.Version
-	dto.Data.Attributes.BaseCurrency = info.BaseCurrency
+	dto.Data.Attributes.BaseCurrency = string(info.BaseCurrency)
 	dto.Data.Attributes.AccountNumber = info.AccountNumber
 	dto.Data.Attributes.BankID = info.BankId
 	dto.Data.Attributes.BankIDCode = info.BankIdCode
@@ -144,7 +298,46 @@ func (info *Account) ToDto() data.AccountDto {
 	dto.Data.Attributes.AccountMatchingOptOut = info.IsAccountMatchingOptOut
 	dto.Data.Attributes.SecondaryIdentification = info.SecondaryIdentification
 	dto.Data.Attributes.Switched = info.IsSwitched
-	return dto
+	dto.Data.Attributes.Status = string(info.Status)
+	dto.Data.Attributes.StatusReason = info.StatusReason
+	dto.Data.Attributes.UserDefinedData = info.UserDefinedData
+	dto.Data.Attributes.ValidationType = info.ValidationType
+	dto.Data.Attributes.ReferenceMask = info.ReferenceMask
+	dto.Data.Attributes.AcceptanceQualifier = info.AcceptanceQualifier
+	dto.Data.Attributes.ProcessingService = info.ProcessingService
+	dto.Data.Attributes.UserDefinedInformation = info.UserDefinedInformation
+	dto.Data.Attributes.CustomerID = info.CustomerId
+	dto.Data.Attributes.Title = info.Title
+	dto.Data.Attributes.FirstName = info.FirstName
+	dto.Data.Attributes.BankAccountName = info.BankAccountName
+	dto.Data.Attributes.PrivateIdentification = info.PrivateIdentification
+	dto.Data.Attributes.OrganisationIdentification = info.OrganisationIdentification
+	dto.Data.Relationships = info.Relationships.toDto()
+	return dto, nil
 }
 
+func (r *Relationships) toDto() *data.Relationships {
+	if r == nil {
+		return nil
+	}
+	links := func(typ string, ids []uuid.UUID) *data.RelationshipData {
+		if ids == nil {
+			return nil
+		}
+		rd := &data.RelationshipData{Data: make([]data.ResourceLink, 0, len(ids))}
+		for _, id := range ids {
+			rd.Data = append(rd.Data, data.ResourceLink{Type: typ, ID: id.String()})
+		}
+		return rd
+	}
+	return &data.Relationships{
+		MasterAccount: links(masterAccountType, r.MasterAccount),
+		AccountEvents: links(accountEventType, r.AccountEvents),
+	}
+}
 
+//GenerateIban builds the IBAN of the account out of its Country, Bic, BankId and AccountNumber.
+//The Bic is only needed in countries where the IBAN carries its bank code (e.g. GB, NL).
+func (info *Account) GenerateIban() (string, error) {
+	return iban.Build(string(info.Country), info.Bic, info.BankId, info.AccountNumber)
+}
@@ -0,0 +1,220 @@
+package form3_task
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	is2 "github.com/matryer/is"
+	"github.com/petegabriel/form3_task/data"
+	"github.com/petegabriel/form3_task/fake"
+	"github.com/petegabriel/form3_task/iban"
+)
+
+func TestNewAccountFromDtoMalformed(t *testing.T) {
+	tests := []struct {
+		name  string
+		edit  func(d *data.Data)
+		field string
+	}{
+		{"malformed id", func(d *data.Data) { d.ID = "not-a-uuid" }, "id"},
+		{"empty id", func(d *data.Data) { d.ID = "" }, "id"},
+		{"malformed organisation id", func(d *data.Data) { d.OrganisationID = "1234" }, "organisation_id"},
+		{"malformed created_on", func(d *data.Data) { d.CreatedOn = "yesterday" }, "created_on"},
+		{"malformed modified_on", func(d *data.Data) { d.ModifiedOn = "2021-13-01T10:00:00Z" }, "modified_on"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			is := is2.New(t)
+			dto := data.NewAccountDto(getRandomId(), getRandomId(), "GB", []string{"Jane"})
+			tt.edit(&dto.Data)
+
+			acc, err := NewAccountFromDto(dto)
+
+			is.True(acc == nil)
+			var decErr *DecodeError
+			is.True(errors.As(err, &decErr))
+			is.Equal(decErr.Field, tt.field)
+		})
+	}
+}
+
+func TestAccountDtoRoundTrip(t *testing.T) {
+	is := is2.New(t)
+	acc := NewAccount([]string{"Jane", "Doe"}, "GB", getRandomId(), getRandomId())
+	acc.Version = 2
+	acc.BankId = "400300"
+	acc.IsSwitched = true
+
+	dto, err := acc.ToDto()
+	is.NoErr(err)
+	back, err := NewAccountFromDto(dto)
+	is.NoErr(err)
+	is.Equal(back, acc)
+}
+
+func TestFullAccountRoundTrip(t *testing.T) {
+	is := is2.New(t)
+	acc := NewAccount([]string{"Jane Doe"}, "GB", getRandomId(), getRandomId())
+	acc.Status = StatusConfirmed
+	acc.StatusReason = "unspecified"
+	acc.UserDefinedData = []UserDefinedData{{Key: "segment", Value: "retail"}, {Key: "source", Value: "web"}}
+	acc.ValidationType = "card"
+	acc.ReferenceMask = "############"
+	acc.AcceptanceQualifier = "same_day"
+	acc.ProcessingService = "ABC Bank"
+	acc.UserDefinedInformation = "onboarded online"
+	acc.CustomerId = "customer-42"
+	acc.Title = "Ms"
+	acc.FirstName = "Jane"
+	acc.BankAccountName = "Jane Doe"
+	acc.PrivateIdentification = &PrivateIdentification{
+		BirthDate:      "1990-07-23",
+		BirthCountry:   "GB",
+		Identification: "13YH458762",
+		Address:        []string{"10 Avenue des Champs"},
+		City:           "London",
+		Country:        "GB",
+	}
+	acc.OrganisationIdentification = &OrganisationIdentification{
+		Identification: "123654",
+		Actors:         []Actor{{Name: []string{"Jeff Page"}, BirthDate: "1970-01-01", Residency: "GB"}},
+	}
+	acc.Relationships = &Relationships{MasterAccount: []uuid.UUID{getRandomId()}}
+
+	dto, err := acc.ToDto()
+	is.NoErr(err)
+	body, err := json.Marshal(dto)
+	is.NoErr(err)
+	var wire data.AccountDto
+	is.NoErr(json.Unmarshal(body, &wire))
+	is.Equal(wire.Data.Attributes.CustomerID, "customer-42")
+	is.Equal(wire.Data.Relationships.MasterAccount.Data[0].Type, "accounts")
+	is.True(wire.Data.Relationships.AccountEvents == nil)
+
+	back, err := NewAccountFromDto(wire)
+	is.NoErr(err)
+	is.Equal(back, acc)
+}
+
+func TestCreateKeepsEveryAttribute(t *testing.T) {
+	is := is2.New(t)
+	srv := fake.NewServer()
+	defer srv.Close()
+	client := NewClient(WithBaseURL(srv.AccountsURL()))
+
+	acc := NewAccount([]string{"Jane Doe"}, "GB", getRandomId(), getRandomId())
+	acc.CustomerId = "customer-42"
+	acc.UserDefinedData = []UserDefinedData{{Key: "segment", Value: "retail"}}
+	acc.PrivateIdentification = &PrivateIdentification{BirthDate: "1990-07-23", City: "London"}
+	created, err := client.CreateAccount(acc)
+	is.NoErr(err)
+
+	found, err := client.GetAccount(created.Id.String())
+	is.NoErr(err)
+	is.Equal(found.CustomerId, acc.CustomerId)
+	is.Equal(found.UserDefinedData, acc.UserDefinedData)
+	is.Equal(found.PrivateIdentification, acc.PrivateIdentification)
+}
+
+func TestOptionalAttributesAreNotSent(t *testing.T) {
+	is := is2.New(t)
+	dto, err := NewAccount([]string{"Jane"}, "GB", getRandomId(), getRandomId()).ToDto()
+	is.NoErr(err)
+	body, err := json.Marshal(dto)
+	is.NoErr(err)
+	for _, attr := range []string{"status", "user_defined_data", "private_identification", "relationships", "customer_id"} {
+		is.True(!strings.Contains(string(body), `"`+attr+`"`))
+	}
+}
+
+func TestMalformedRelationship(t *testing.T) {
+	is := is2.New(t)
+	dto := data.NewAccountDto(getRandomId(), getRandomId(), "GB", []string{"Jane"})
+	dto.Data.Relationships = &data.Relationships{
+		AccountEvents: &data.RelationshipData{Data: []data.ResourceLink{{Type: "account_events", ID: "42"}}},
+	}
+	_, err := NewAccountFromDto(dto)
+	var decErr *DecodeError
+	is.True(errors.As(err, &decErr))
+	is.Equal(decErr.Field, "relationships.account_events")
+}
+
+func TestAccountTimestampsRoundTrip(t *testing.T) {
+	is := is2.New(t)
+	dto := data.NewAccountDto(getRandomId(), getRandomId(), "GB", []string{"Jane"})
+	dto.Data.CreatedOn = "2021-03-25T13:58:34.556Z"
+	dto.Data.ModifiedOn = "2021-03-26T09:00:00.1+01:00"
+
+	acc, err := NewAccountFromDto(dto)
+	is.NoErr(err)
+	is.True(acc.CreatedOn.Equal(time.Date(2021, 3, 25, 13, 58, 34, 556000000, time.UTC)))
+	is.True(acc.ModifiedOn.Equal(time.Date(2021, 3, 26, 8, 0, 0, 100000000, time.UTC)))
+
+	back, err := acc.ToDto()
+	is.NoErr(err)
+	is.Equal(back.Data.CreatedOn, "2021-03-25T13:58:34.556Z")
+	is.Equal(back.Data.ModifiedOn, "2021-03-26T08:00:00.1Z")
+}
+
+func TestNewAccountHasNoTimestamps(t *testing.T) {
+	is := is2.New(t)
+	acc := NewAccount([]string{"Jane"}, "GB", getRandomId(), getRandomId())
+	is.True(acc.CreatedOn.IsZero())
+
+	dto, err := acc.ToDto()
+	is.NoErr(err)
+	body, err := json.Marshal(dto)
+	is.NoErr(err)
+	//the api sets the timestamps, they are not sent on create
+	is.True(!strings.Contains(string(body), "created_on"))
+	is.True(!strings.Contains(string(body), "modified_on"))
+
+	back, err := NewAccountFromDto(dto)
+	is.NoErr(err)
+	is.True(back.CreatedOn.IsZero())
+	is.True(back.ModifiedOn.IsZero())
+}
+
+func TestToDtoWithoutIds(t *testing.T) {
+	is := is2.New(t)
+	_, err := NewAccount([]string{"Jane"}, "GB", uuid.Nil, getRandomId()).ToDto()
+	is.True(errors.Is(err, ErrInvalidID))
+	_, err = NewAccount([]string{"Jane"}, "GB", getRandomId(), uuid.Nil).ToDto()
+	is.True(errors.Is(err, ErrInvalidID))
+}
+
+func TestClientReportsMalformedAccounts(t *testing.T) {
+	is := is2.New(t)
+	id := getRandomId()
+	dto := data.NewAccountDto(id, getRandomId(), "GB", []string{"Jane"})
+	dto.Data.OrganisationID = "garbage"
+	client := NewClient(WithGateway(&stubGateway{dto: dto}))
+
+	_, err := client.GetAccount(id.String())
+	var decErr *DecodeError
+	is.True(errors.As(err, &decErr))
+	is.Equal(decErr.Value, "garbage")
+
+	_, err = client.ListAccounts(ListOptions{})
+	is.True(errors.As(err, &decErr))
+
+	_, err = client.UpdateAccount(id.String(), 0, &AccountPatch{Name: []string{"Jane"}})
+	is.True(errors.As(err, &decErr))
+}
+
+func TestGenerateIban(t *testing.T) {
+	is := is2.New(t)
+	acc := NewAccount([]string{"Samantha Holder"}, "GB", getRandomId(), getRandomId())
+	acc.Bic = "NWBKGB22"
+	acc.BankId = "400300"
+	acc.AccountNumber = "41426819"
+
+	generated, err := acc.GenerateIban()
+	is.NoErr(err)
+	is.Equal(generated, "GB16NWBK40030041426819")
+	is.NoErr(iban.Validate(generated))
+}
@@ -0,0 +1,55 @@
+//Package auth provides authenticators for the requests sent to the Form3 account api:
+//bearer tokens and http signatures (draft-cavage-http-signatures) made with RSA or
+//ECDSA keys, along with a Verifier checking those signatures on the server side.
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+var (
+	//ErrMissingCredentials is returned when there is no token or signature to check or to send.
+	ErrMissingCredentials = errors.New("auth: missing credentials")
+
+	//ErrUnsupportedKey is returned for keys which are neither RSA nor ECDSA keys.
+	ErrUnsupportedKey = errors.New("auth: unsupported key type")
+
+	//ErrUnknownKey is returned when the signature was made with a key the Verifier does not know.
+	ErrUnknownKey = errors.New("auth: unknown key id")
+
+	//ErrInvalidSignature is returned when the signature does not match the request.
+	ErrInvalidSignature = errors.New("auth: invalid signature")
+
+	//ErrDigestMismatch is returned when the Digest header does not match the body of the request.
+	ErrDigestMismatch = errors.New("auth: digest does not match the body")
+
+	//ErrStaleRequest is returned when the Date header is too far from the current time.
+	ErrStaleRequest = errors.New("auth: request date out of the allowed window")
+)
+
+//BearerToken authenticates the requests with a static bearer token.
+type BearerToken string
+
+//Authenticate sets the Authorization header of req.
+func (t BearerToken) Authenticate(req *http.Request) error {
+	if t == "" {
+		return ErrMissingCredentials
+	}
+	req.Header.Set("Authorization", "Bearer "+string(t))
+	return nil
+}
+
+//TokenFunc authenticates the requests with a bearer token obtained for each request,
+//e.g. from an OAuth2 token source caching and refreshing the token.
+type TokenFunc func(ctx context.Context) (string, error)
+
+//Authenticate sets the Authorization header of req with the token given by f.
+func (f TokenFunc) Authenticate(req *http.Request) error {
+	token, err := f(req.Context())
+	if err != nil {
+		return err
+	}
+	return BearerToken(token).Authenticate(req)
+}
@@ -0,0 +1,174 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	is2 "github.com/matryer/is"
+)
+
+func newKeys(t *testing.T) map[string]crypto.Signer {
+	is := is2.New(t)
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	is.NoErr(err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	is.NoErr(err)
+	return map[string]crypto.Signer{"rsa-key": rsaKey, "ec-key": ecKey}
+}
+
+func publicKeys(keys map[string]crypto.Signer) map[string]crypto.PublicKey {
+	pub := map[string]crypto.PublicKey{}
+	for id, key := range keys {
+		pub[id] = key.Public()
+	}
+	return pub
+}
+
+func signedRequest(t *testing.T, s *Signer, method, uri, body string) *http.Request {
+	is := is2.New(t)
+	req, err := http.NewRequest(method, uri, strings.NewReader(body))
+	is.NoErr(err)
+	is.NoErr(s.Authenticate(req))
+	return req
+}
+
+func TestSignAndVerify(t *testing.T) {
+	keys := newKeys(t)
+	verifier := NewVerifier(publicKeys(keys))
+	var received string
+	srv := httptest.NewServer(verifier.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		received = string(body)
+		w.WriteHeader(http.StatusCreated)
+	})))
+	defer srv.Close()
+
+	for id, key := range keys {
+		t.Run(id, func(t *testing.T) {
+			is := is2.New(t)
+			signer, err := NewSigner(id, key)
+			is.NoErr(err)
+			req := signedRequest(t, signer, http.MethodPost, srv.URL+"/v1/organisation/accounts?x=1", `{"data":{}}`)
+			is.True(strings.HasPrefix(req.Header.Get("Authorization"), `Signature keyId="`+id+`",algorithm="`))
+			is.True(strings.Contains(req.Header.Get("Authorization"), `headers="(request-target) host date digest"`))
+
+			resp, err := srv.Client().Do(req)
+			is.NoErr(err)
+			resp.Body.Close()
+			is.Equal(resp.StatusCode, http.StatusCreated)
+			is.Equal(received, `{"data":{}}`) //the body is still readable after the check
+		})
+	}
+}
+
+func TestVerifyRejects(t *testing.T) {
+	keys := newKeys(t)
+	signer, _ := NewSigner("rsa-key", keys["rsa-key"])
+	other, _ := NewSigner("ec-key", keys["ec-key"])
+	now := time.Date(2021, 3, 25, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		req  func() *http.Request
+		err  error
+	}{
+		{"no signature", func() *http.Request {
+			req, _ := http.NewRequest(http.MethodGet, "http://api.test/accounts", nil)
+			return req
+		}, ErrMissingCredentials},
+		{"unknown key", func() *http.Request {
+			s := *signer
+			s.KeyID = "other"
+			return signedRequest(t, &s, http.MethodGet, "http://api.test/accounts", "")
+		}, ErrUnknownKey},
+		{"other path", func() *http.Request {
+			req := signedRequest(t, signer, http.MethodDelete, "http://api.test/accounts/1?version=0", "")
+			req.URL.Path = "/accounts/2"
+			return req
+		}, ErrInvalidSignature},
+		{"other host", func() *http.Request {
+			req := signedRequest(t, signer, http.MethodGet, "http://api.test/accounts", "")
+			req.Host = "evil.test"
+			return req
+		}, ErrInvalidSignature},
+		{"key of someone else", func() *http.Request {
+			req := signedRequest(t, other, http.MethodGet, "http://api.test/accounts", "")
+			req.Header.Set("Authorization", strings.Replace(req.Header.Get("Authorization"), `keyId="ec-key",algorithm="ecdsa-sha256"`, `keyId="rsa-key",algorithm="rsa-sha256"`, 1))
+			return req
+		}, ErrInvalidSignature},
+		{"date not signed", func() *http.Request {
+			s := *signer
+			s.Headers = []string{RequestTarget, Host, Digest}
+			return signedRequest(t, &s, http.MethodGet, "http://api.test/accounts", "")
+		}, ErrInvalidSignature},
+		{"body changed", func() *http.Request {
+			req := signedRequest(t, signer, http.MethodPost, "http://api.test/accounts", `{"data":{}}`)
+			req.Body = ioutil.NopCloser(strings.NewReader(`{"data":{"id":"1"}}`))
+			req.GetBody = nil
+			return req
+		}, ErrDigestMismatch},
+		{"old request", func() *http.Request {
+			s := *signer
+			s.Now = func() time.Time { return now.Add(-10 * time.Minute) }
+			return signedRequest(t, &s, http.MethodGet, "http://api.test/accounts", "")
+		}, ErrStaleRequest},
+	}
+	verifier := NewVerifier(publicKeys(keys))
+	verifier.Now = func() time.Time { return now }
+	signer.Now = verifier.Now
+	other.Now = verifier.Now
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			is := is2.New(t)
+			err := verifier.Verify(tt.req())
+			is.True(errors.Is(err, tt.err))
+		})
+	}
+}
+
+func TestSigningString(t *testing.T) {
+	is := is2.New(t)
+	req, _ := http.NewRequest(http.MethodPost, "https://api.form3.tech/v1/organisation/accounts?page=1", strings.NewReader("{}"))
+	req.Header.Set("Date", "Thu, 25 Mar 2021 10:00:00 GMT")
+	req.Header.Set("Digest", digest([]byte("{}")))
+
+	str, err := signingString(req, DefaultHeaders)
+	is.NoErr(err)
+	is.Equal(str, "(request-target): post /v1/organisation/accounts?page=1\n"+
+		"host: api.form3.tech\n"+
+		"date: Thu, 25 Mar 2021 10:00:00 GMT\n"+
+		"digest: SHA-256=RBNvo1WzZ4oRRq0W9+hknpT7T8If536DEMBg9hyq/4o=")
+}
+
+func TestUnsupportedKey(t *testing.T) {
+	is := is2.New(t)
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	is.NoErr(err)
+	_, err = NewSigner("ed-key", key)
+	is.True(errors.Is(err, ErrUnsupportedKey))
+}
+
+func TestBearerToken(t *testing.T) {
+	is := is2.New(t)
+	req, _ := http.NewRequest(http.MethodGet, "http://api.test/accounts", nil)
+	is.NoErr(BearerToken("abc").Authenticate(req))
+	is.Equal(req.Header.Get("Authorization"), "Bearer abc")
+
+	is.True(errors.Is(BearerToken("").Authenticate(req), ErrMissingCredentials))
+
+	failing := errors.New("token expired")
+	tokens := TokenFunc(func(ctx context.Context) (string, error) { return "", failing })
+	is.True(errors.Is(tokens.Authenticate(req), failing))
+}
@@ -0,0 +1,169 @@
+package auth
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+)
+
+//Names of the pseudo header and headers covered by the signatures by default.
+const (
+	RequestTarget = "(request-target)"
+	Host          = "host"
+	Date          = "date"
+	Digest        = "digest"
+)
+
+//DefaultHeaders are the headers signed by a Signer unless told otherwise.
+var DefaultHeaders = []string{RequestTarget, Host, Date, Digest}
+
+//Signer authenticates the requests with an http signature made with an RSA or ECDSA
+//private key, sent in the Authorization header:
+//
+//  Authorization: Signature keyId="...",algorithm="rsa-sha256",headers="(request-target) host date digest",signature="..."
+//
+//The Date and Digest (SHA-256 of the body) headers are set when missing.
+//A Signer is safe for concurrent use.
+type Signer struct {
+
+	//KeyID identifies the public key the account api must use to check the signature.
+	KeyID string
+
+	//Key is the private key, a *rsa.PrivateKey or an *ecdsa.PrivateKey.
+	Key crypto.Signer
+
+	//Headers lists the (lowercase) headers covered by the signature, DefaultHeaders when empty.
+	Headers []string
+
+	//Now gives the time set in the Date header, time.Now when nil.
+	Now func() time.Time
+}
+
+//NewSigner creates a Signer of the default headers with the given key,
+//which must be a *rsa.PrivateKey or an *ecdsa.PrivateKey.
+func NewSigner(keyId string, key crypto.Signer) (*Signer, error) {
+	if _, err := algorithm(key.Public()); err != nil {
+		return nil, err
+	}
+	return &Signer{KeyID: keyId, Key: key}, nil
+}
+
+//Authenticate sets the Date, Digest and Authorization headers of req.
+func (s *Signer) Authenticate(req *http.Request) error {
+	alg, err := algorithm(s.Key.Public())
+	if err != nil {
+		return err
+	}
+	headers := s.Headers
+	if len(headers) == 0 {
+		headers = DefaultHeaders
+	}
+
+	if req.Header.Get("Date") == "" {
+		now := time.Now
+		if s.Now != nil {
+			now = s.Now
+		}
+		req.Header.Set("Date", now().UTC().Format(http.TimeFormat))
+	}
+	if req.Header.Get("Digest") == "" {
+		body, err := readBody(req)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Digest", digest(body))
+	}
+
+	str, err := signingString(req, headers)
+	if err != nil {
+		return err
+	}
+	hash := sha256.Sum256([]byte(str))
+	sig, err := s.Key.Sign(rand.Reader, hash[:], crypto.SHA256)
+	if err != nil {
+		return fmt.Errorf("auth: error signing request: %w", err)
+	}
+	req.Header.Set("Authorization", fmt.Sprintf(`Signature keyId="%s",algorithm="%s",headers="%s",signature="%s"`,
+		s.KeyID, alg, strings.Join(headers, " "), base64.StdEncoding.EncodeToString(sig)))
+	return nil
+}
+
+//algorithm names the signature algorithm used with the given key.
+func algorithm(key crypto.PublicKey) (string, error) {
+	switch key.(type) {
+	case *rsa.PublicKey:
+		return "rsa-sha256", nil
+	case *ecdsa.PublicKey:
+		return "ecdsa-sha256", nil
+	default:
+		return "", fmt.Errorf("%w: %T", ErrUnsupportedKey, key)
+	}
+}
+
+//signingString builds the string signed out of the given headers of req, one 'name: value' per line.
+func signingString(req *http.Request, headers []string) (string, error) {
+	lines := make([]string, 0, len(headers))
+	for _, name := range headers {
+		name = strings.ToLower(name)
+		var value string
+		switch name {
+		case RequestTarget:
+			value = strings.ToLower(req.Method) + " " + req.URL.RequestURI()
+		case Host:
+			value = req.Host
+			if value == "" {
+				value = req.URL.Host
+			}
+		default:
+			values, found := req.Header[http.CanonicalHeaderKey(name)]
+			if !found {
+				return "", fmt.Errorf("%w: header %s is missing", ErrMissingCredentials, name)
+			}
+			value = strings.Join(values, ", ")
+		}
+		lines = append(lines, name+": "+value)
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+//digest computes the value of the Digest header of a body.
+func digest(body []byte) string {
+	sum := sha256.Sum256(body)
+	return "SHA-256=" + base64.StdEncoding.EncodeToString(sum[:])
+}
+
+//readBody returns the body of req, leaving it in place to be read again.
+func readBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	var rd io.ReadCloser
+	var err error
+	if req.GetBody != nil {
+		rd, err = req.GetBody()
+		if err != nil {
+			return nil, fmt.Errorf("auth: error reading body: %w", err)
+		}
+	} else {
+		rd = req.Body
+	}
+	defer rd.Close()
+	body, err := ioutil.ReadAll(rd)
+	if err != nil {
+		return nil, fmt.Errorf("auth: error reading body: %w", err)
+	}
+	if req.GetBody == nil {
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+	return body, nil
+}
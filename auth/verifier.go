@@ -0,0 +1,164 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+)
+
+//DefaultMaxSkew is how far the Date of a request can be from the current time by default.
+const DefaultMaxSkew = 5 * time.Minute
+
+//Verifier checks the http signatures made by a Signer, as the account api does.
+//It is meant to test the signing of requests against a local server.
+type Verifier struct {
+
+	//Keys are the public keys (*rsa.PublicKey or *ecdsa.PublicKey) by key id.
+	Keys map[string]crypto.PublicKey
+
+	//Required lists the headers every signature must cover, DefaultHeaders when empty.
+	Required []string
+
+	//MaxSkew is how far the Date of a request can be from Now, DefaultMaxSkew when zero.
+	MaxSkew time.Duration
+
+	//Now gives the current time, time.Now when nil.
+	Now func() time.Time
+}
+
+//NewVerifier creates a Verifier of the signatures made with the given keys
+//and covering at least the default headers.
+func NewVerifier(keys map[string]crypto.PublicKey) *Verifier {
+	return &Verifier{Keys: keys}
+}
+
+var signatureParam = regexp.MustCompile(`(\w+)="([^"]*)"`)
+
+//Verify checks the signature in the Authorization header of req, the Digest of its body
+//and its Date. The body of req is left in place to be read again.
+func (v *Verifier) Verify(req *http.Request) error {
+	header := req.Header.Get("Authorization")
+	if !strings.HasPrefix(header, "Signature ") {
+		return ErrMissingCredentials
+	}
+	params := map[string]string{}
+	for _, m := range signatureParam.FindAllStringSubmatch(header, -1) {
+		params[m[1]] = m[2]
+	}
+
+	key, found := v.Keys[params["keyId"]]
+	if !found {
+		return fmt.Errorf("%w: %q", ErrUnknownKey, params["keyId"])
+	}
+	alg, err := algorithm(key)
+	if err != nil {
+		return err
+	}
+	if params["algorithm"] != alg {
+		return fmt.Errorf("%w: algorithm %q does not match the key", ErrInvalidSignature, params["algorithm"])
+	}
+
+	headers := strings.Fields(strings.ToLower(params["headers"]))
+	if len(headers) == 0 {
+		//as per the specification, only the date is signed when the list is missing
+		headers = []string{Date}
+	}
+	required := v.Required
+	if len(required) == 0 {
+		required = DefaultHeaders
+	}
+	for _, name := range required {
+		if !contains(headers, name) {
+			return fmt.Errorf("%w: header %s is not signed", ErrInvalidSignature, name)
+		}
+	}
+
+	str, err := signingString(req, headers)
+	if err != nil {
+		return err
+	}
+	sig, err := base64.StdEncoding.DecodeString(params["signature"])
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidSignature, err)
+	}
+	if !verify(key, str, sig) {
+		return ErrInvalidSignature
+	}
+
+	if err := v.checkDate(req); err != nil {
+		return err
+	}
+	if contains(headers, Digest) {
+		body, err := readBody(req)
+		if err != nil {
+			return err
+		}
+		if subtle.ConstantTimeCompare([]byte(req.Header.Get("Digest")), []byte(digest(body))) != 1 {
+			return ErrDigestMismatch
+		}
+	}
+	return nil
+}
+
+//Handler only lets through to next the requests with a valid signature. The
+//others are answered with 401 and an error body in the format of the account api.
+func (v *Verifier) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := v.Verify(r); err != nil {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusUnauthorized)
+			_ = json.NewEncoder(w).Encode(map[string]string{"error_message": err.Error()})
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (v *Verifier) checkDate(req *http.Request) error {
+	date, err := http.ParseTime(req.Header.Get("Date"))
+	if err != nil {
+		return fmt.Errorf("%w: missing or malformed Date header", ErrStaleRequest)
+	}
+	now := time.Now
+	if v.Now != nil {
+		now = v.Now
+	}
+	skew := v.MaxSkew
+	if skew == 0 {
+		skew = DefaultMaxSkew
+	}
+	if diff := now().Sub(date); diff > skew || diff < -skew {
+		return fmt.Errorf("%w: %s", ErrStaleRequest, req.Header.Get("Date"))
+	}
+	return nil
+}
+
+func verify(key crypto.PublicKey, str string, sig []byte) bool {
+	hash := sha256.Sum256([]byte(str))
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		return rsa.VerifyPKCS1v15(k, crypto.SHA256, hash[:], sig) == nil
+	case *ecdsa.PublicKey:
+		return ecdsa.VerifyASN1(k, hash[:], sig)
+	default:
+		return false
+	}
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
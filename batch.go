@@ -0,0 +1,215 @@
+package form3_task
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/petegabriel/form3_task/data"
+)
+
+//DefaultBatchConcurrency is the number of requests a batch runs at the same time by default.
+const DefaultBatchConcurrency = 4
+
+//ErrSkipped is the error of the batch items which were not processed because
+//the batch was stopped, by its context or by the failure of another item.
+var ErrSkipped = errors.New("not processed, the batch was stopped")
+
+//BatchOptions controls how the items of a batch are processed.
+type BatchOptions struct {
+
+	//Concurrency is the number of items processed at the same time, DefaultBatchConcurrency when zero.
+	Concurrency int
+
+	//RateLimit caps the number of items started per second, across all workers. No limit when zero.
+	RateLimit float64
+
+	//StopOnError stops starting new items once one fails. Items already
+	//started are completed, the others fail with ErrSkipped.
+	StopOnError bool
+}
+
+//CreateResult is the outcome of creating one account of a batch.
+type CreateResult struct {
+
+	//Account is the account as created by the api, nil when Err is set.
+	Account *Account
+
+	//Err is the reason why the account was not created.
+	Err error
+}
+
+//DeleteRequest identifies an account to delete by its id and current version.
+type DeleteRequest struct {
+	Id      string
+	Version int
+}
+
+//DeleteResult is the outcome of deleting one account of a batch.
+type DeleteResult struct {
+
+	//Id of the account to delete.
+	Id string
+
+	//Err is the reason why the account was not deleted.
+	Err error
+}
+
+//BatchError is returned by batch operations when some items were not processed successfully.
+//The outcome of every item is found in the results.
+type BatchError struct {
+
+	//Failed is the number of items which failed, skipped ones included.
+	Failed int
+
+	//Skipped is the number of items which were not processed.
+	Skipped int
+
+	//Err is the first error that occurred, or the context error when the batch was cancelled.
+	Err error
+}
+
+func (e *BatchError) Error() string {
+	return fmt.Sprintf("%d items of the batch failed (%d skipped): %s", e.Failed, e.Skipped, e.Err)
+}
+
+//Unwrap returns the first error that occurred.
+func (e *BatchError) Unwrap() error {
+	return e.Err
+}
+
+//CreateAccounts creates the given accounts concurrently as set by opts.
+//Results are in the same order as the accounts.
+func (c *Client) CreateAccounts(accounts []*Account, opts BatchOptions) ([]CreateResult, error) {
+	return c.CreateAccountsContext(context.Background(), accounts, opts)
+}
+
+//CreateAccountsContext is like CreateAccounts but the requests are bound to ctx.
+//Once ctx is done, the accounts not yet started fail with ErrSkipped.
+//Nil accounts fail with a *ValidationError.
+func (c *Client) CreateAccountsContext(ctx context.Context, accounts []*Account, opts BatchOptions) ([]CreateResult, error) {
+	results := make([]CreateResult, len(accounts))
+	err := runBatch(ctx, len(accounts), opts, func(ctx context.Context, i int) error {
+		if accounts[i] == nil {
+			results[i].Err = &ValidationError{Fields: []FieldError{{Field: "data", Rule: "required", Message: "is required"}}}
+			return results[i].Err
+		}
+		acc, err := c.CreateAccountContext(ctx, accounts[i])
+		results[i] = CreateResult{Account: acc, Err: err}
+		return err
+	}, func(i int) {
+		results[i].Err = ErrSkipped
+	})
+	return results, err
+}
+
+//DeleteAccounts deletes the given accounts concurrently as set by opts.
+//Results are in the same order as the requests.
+func (c *Client) DeleteAccounts(reqs []DeleteRequest, opts BatchOptions) ([]DeleteResult, error) {
+	return c.DeleteAccountsContext(context.Background(), reqs, opts)
+}
+
+//DeleteAccountsContext is like DeleteAccounts but the requests are bound to ctx.
+//Once ctx is done, the accounts not yet started fail with ErrSkipped.
+func (c *Client) DeleteAccountsContext(ctx context.Context, reqs []DeleteRequest, opts BatchOptions) ([]DeleteResult, error) {
+	results := make([]DeleteResult, len(reqs))
+	for i, req := range reqs {
+		results[i].Id = req.Id
+	}
+	err := runBatch(ctx, len(reqs), opts, func(ctx context.Context, i int) error {
+		results[i].Err = c.DeleteAccountContext(ctx, reqs[i].Id, reqs[i].Version)
+		return results[i].Err
+	}, func(i int) {
+		results[i].Err = ErrSkipped
+	})
+	return results, err
+}
+
+//runBatch calls process for each of the n items from a pool of workers, and skip
+//for those which are not processed. Returns a *BatchError when any item failed.
+func runBatch(ctx context.Context, n int, opts BatchOptions, process func(ctx context.Context, i int) error, skip func(i int)) error {
+	workers := opts.Concurrency
+	if workers <= 0 {
+		workers = DefaultBatchConcurrency
+	}
+	if workers > n {
+		workers = n
+	}
+	var limiter *data.RateLimiter
+	if opts.RateLimit > 0 {
+		limiter = data.NewRateLimiter(data.RateLimit{RequestsPerSecond: opts.RateLimit})
+	}
+
+	var (
+		mu       sync.Mutex
+		failed   int
+		skipped  int
+		firstErr error
+		stopped  int32
+	)
+	fail := func(err error, wasSkipped bool) {
+		mu.Lock()
+		defer mu.Unlock()
+		failed++
+		if wasSkipped {
+			skipped++
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				if atomic.LoadInt32(&stopped) == 1 || ctx.Err() != nil {
+					skip(i)
+					fail(ErrSkipped, true)
+					continue
+				}
+				if err := process(ctx, i); err != nil {
+					fail(err, false)
+					if opts.StopOnError {
+						atomic.StoreInt32(&stopped, 1)
+					}
+				}
+			}
+		}()
+	}
+
+	next := 0
+dispatch:
+	for ; next < n; next++ {
+		if atomic.LoadInt32(&stopped) == 1 {
+			break
+		}
+		if limiter != nil && limiter.Wait(ctx) != nil {
+			break dispatch
+		}
+		select {
+		case jobs <- next:
+		case <-ctx.Done():
+			break dispatch
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	for i := next; i < n; i++ {
+		skip(i)
+		fail(ErrSkipped, true)
+	}
+	if failed == 0 {
+		return nil
+	}
+	if ctx.Err() != nil && skipped > 0 {
+		firstErr = ctx.Err()
+	}
+	return &BatchError{Failed: failed, Skipped: skipped, Err: firstErr}
+}
@@ -0,0 +1,161 @@
+package form3_task
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	is2 "github.com/matryer/is"
+	"github.com/petegabriel/form3_task/fake"
+)
+
+//newBatchClient returns a client of a fake account api which handles each request
+//after the given delay, and the highest number of requests it handled at once.
+func newBatchClient(t *testing.T, delay time.Duration) (*Client, *int32) {
+	var current, highest int32
+	api := fake.NewHandler()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&current, 1)
+		defer atomic.AddInt32(&current, -1)
+		for {
+			max := atomic.LoadInt32(&highest)
+			if n <= max || atomic.CompareAndSwapInt32(&highest, max, n) {
+				break
+			}
+		}
+		time.Sleep(delay)
+		api.ServeHTTP(w, r)
+	}))
+	t.Cleanup(srv.Close)
+	return NewClient(WithBaseURL(srv.URL + fake.AccountsPath)), &highest
+}
+
+func newBatch(n int) []*Account {
+	accounts := make([]*Account, n)
+	for i := range accounts {
+		accounts[i] = NewAccount([]string{"Jane Doe"}, "GB", getRandomId(), getRandomId())
+	}
+	return accounts
+}
+
+func TestCreateAccountsKeepsOrder(t *testing.T) {
+	is := is2.New(t)
+	client, highest := newBatchClient(t, 10*time.Millisecond)
+	accounts := newBatch(12)
+	accounts[5].Name = []string{""} //not valid
+
+	results, err := client.CreateAccounts(accounts, BatchOptions{Concurrency: 3})
+
+	is.Equal(len(results), len(accounts))
+	for i, res := range results {
+		if i == 5 {
+			is.True(errors.Is(res.Err, ErrValidation))
+			is.True(res.Account == nil)
+			continue
+		}
+		is.NoErr(res.Err)
+		is.Equal(res.Account.Id, accounts[i].Id)
+	}
+	var batchErr *BatchError
+	is.True(errors.As(err, &batchErr))
+	is.Equal(batchErr.Failed, 1)
+	is.Equal(batchErr.Skipped, 0)
+	is.True(errors.Is(err, ErrValidation))
+	is.Equal(atomic.LoadInt32(highest), int32(3))
+}
+
+func TestCreateAccountsWithNilAccount(t *testing.T) {
+	is := is2.New(t)
+	client, _ := newBatchClient(t, 0)
+	accounts := newBatch(3)
+	accounts[1] = nil
+
+	results, err := client.CreateAccounts(accounts, BatchOptions{Concurrency: 2})
+
+	var valErr *ValidationError
+	is.True(errors.As(results[1].Err, &valErr))
+	is.Equal(valErr.Fields[0].Field, "data")
+	is.True(results[1].Account == nil)
+	is.NoErr(results[0].Err)
+	is.NoErr(results[2].Err)
+	var batchErr *BatchError
+	is.True(errors.As(err, &batchErr))
+	is.Equal(batchErr.Failed, 1)
+}
+
+func TestDeleteAccounts(t *testing.T) {
+	is := is2.New(t)
+	client, _ := newBatchClient(t, 0)
+	accounts := newBatch(5)
+	_, err := client.CreateAccounts(accounts, BatchOptions{})
+	is.NoErr(err)
+
+	reqs := make([]DeleteRequest, len(accounts))
+	for i, acc := range accounts {
+		reqs[i] = DeleteRequest{Id: acc.Id.String()}
+	}
+	reqs[2].Version = 7
+
+	results, err := client.DeleteAccounts(reqs, BatchOptions{Concurrency: 10})
+	is.True(errors.Is(err, ErrVersionConflict))
+	for i, res := range results {
+		is.Equal(res.Id, reqs[i].Id)
+		if i == 2 {
+			is.True(errors.Is(res.Err, ErrVersionConflict))
+		} else {
+			is.NoErr(res.Err)
+		}
+	}
+
+	results, err = client.DeleteAccounts(nil, BatchOptions{})
+	is.NoErr(err)
+	is.Equal(len(results), 0)
+}
+
+func TestBatchStopsOnError(t *testing.T) {
+	is := is2.New(t)
+	client, _ := newBatchClient(t, 5*time.Millisecond)
+	accounts := newBatch(20)
+	accounts[1].Id = accounts[0].Id //duplicate
+
+	results, err := client.CreateAccounts(accounts, BatchOptions{Concurrency: 1, StopOnError: true})
+
+	is.True(errors.Is(err, ErrDuplicate))
+	is.NoErr(results[0].Err)
+	is.True(errors.Is(results[1].Err, ErrDuplicate))
+	for _, res := range results[2:] {
+		is.True(errors.Is(res.Err, ErrSkipped))
+	}
+	var batchErr *BatchError
+	is.True(errors.As(err, &batchErr))
+	is.Equal(batchErr.Skipped, 18)
+	is.Equal(batchErr.Failed, 19)
+}
+
+func TestBatchCancelled(t *testing.T) {
+	is := is2.New(t)
+	client, _ := newBatchClient(t, 20*time.Millisecond)
+	ctx, cancel := context.WithTimeout(context.Background(), 70*time.Millisecond)
+	defer cancel()
+
+	results, err := client.CreateAccountsContext(ctx, newBatch(50), BatchOptions{Concurrency: 2})
+
+	is.True(errors.Is(err, context.DeadlineExceeded))
+	is.True(errors.Is(results[len(results)-1].Err, ErrSkipped))
+	is.NoErr(results[0].Err)
+}
+
+func TestBatchRateLimit(t *testing.T) {
+	is := is2.New(t)
+	client, _ := newBatchClient(t, 0)
+	start := time.Now()
+
+	_, err := client.CreateAccounts(newBatch(6), BatchOptions{Concurrency: 6, RateLimit: 100})
+
+	is.NoErr(err)
+	is.True(time.Since(start) >= 50*time.Millisecond) //5 waits of 10ms between the 6 items
+}
@@ -0,0 +1,276 @@
+//Package breaker stops calling the account api for a while once it keeps failing,
+//so that callers fail fast with ErrCircuitOpen instead of waiting for timeouts.
+//
+//The circuit starts closed: calls go through and consecutive failures are counted.
+//After FailureThreshold of them it opens: calls fail right away. After CoolDown it is
+//half-open: a few trial calls go through, closing the circuit if they succeed and
+//opening it again if any fails.
+package breaker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/petegabriel/form3_task/data"
+)
+
+//ErrCircuitOpen is returned, without calling the account api, while the circuit is open.
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+//State of a circuit.
+type State int
+
+const (
+	Closed State = iota
+	Open
+	HalfOpen
+)
+
+func (s State) String() string {
+	switch s {
+	case Closed:
+		return "closed"
+	case Open:
+		return "open"
+	case HalfOpen:
+		return "half-open"
+	default:
+		return fmt.Sprintf("State(%d)", int(s))
+	}
+}
+
+//Defaults of the Settings left to zero.
+const (
+	DefaultFailureThreshold = 5
+	DefaultCoolDown         = 30 * time.Second
+	DefaultHalfOpenRequests = 1
+)
+
+//Settings configure a Breaker.
+type Settings struct {
+
+	//FailureThreshold is the number of consecutive failures opening the circuit.
+	FailureThreshold int
+
+	//CoolDown is how long the circuit stays open before letting trial calls through.
+	CoolDown time.Duration
+
+	//HalfOpenRequests is the number of trial calls let through when half-open,
+	//all of which must succeed to close the circuit.
+	HalfOpenRequests int
+
+	//IsFailure reports whether an error counts as a failure of the account api,
+	//IsFailure (the package function) when nil.
+	IsFailure func(err error) bool
+
+	//OnStateChange, when set, is called on every change of state, outside of any lock.
+	OnStateChange func(from, to State)
+}
+
+//IsFailure is the default classification of errors: the account api failing to answer
+//(network errors, timeouts) or answering with 429 or a 5xx status counts as a failure.
+//Errors caused by the request itself (e.g. not found, validation, version conflict) or by
+//the caller (a cancelled context, the client rate limit) do not. Calls made through
+//DoContext or the Decorator which the caller gave up on do not count as successes either.
+func IsFailure(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, data.ErrRateLimited) ||
+		errors.Is(err, data.ErrAuthentication) || errors.Is(err, ErrCircuitOpen) {
+		return false
+	}
+	var apiErr *data.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode >= http.StatusInternalServerError || apiErr.StatusCode == http.StatusTooManyRequests
+	}
+	return true
+}
+
+//Breaker is a circuit breaker shared by the gateways it decorates.
+//It is safe for concurrent use.
+type Breaker struct {
+	settings Settings
+	now      func() time.Time
+
+	mu         sync.Mutex
+	state      State
+	generation uint64 //changes with the state, to ignore the outcome of calls made in a previous one
+	failures   int
+	openedAt   time.Time
+	trials     int //trial calls started while half-open
+	successes  int //trial calls which succeeded while half-open
+}
+
+//New creates a closed Breaker.
+func New(s Settings) *Breaker {
+	if s.FailureThreshold <= 0 {
+		s.FailureThreshold = DefaultFailureThreshold
+	}
+	if s.CoolDown <= 0 {
+		s.CoolDown = DefaultCoolDown
+	}
+	if s.HalfOpenRequests <= 0 {
+		s.HalfOpenRequests = DefaultHalfOpenRequests
+	}
+	if s.IsFailure == nil {
+		s.IsFailure = IsFailure
+	}
+	return &Breaker{settings: s, now: time.Now}
+}
+
+//State returns the current state of the circuit.
+func (b *Breaker) State() State {
+	b.mu.Lock()
+	from, to := b.refresh()
+	b.mu.Unlock()
+	b.notify(from, to)
+	return to
+}
+
+//Do calls f if the circuit lets it through and records its outcome.
+//Returns ErrCircuitOpen without calling f otherwise.
+func (b *Breaker) Do(f func() error) error {
+	return b.DoContext(context.Background(), f)
+}
+
+//DoContext is like Do for a call bound to ctx. A call failing once ctx is done, or with
+//context.Canceled, is neither a failure nor a success: the caller gave up on it, so it
+//says nothing about the account api. When half-open, it frees its trial slot.
+func (b *Breaker) DoContext(ctx context.Context, f func() error) error {
+	gen, err := b.before()
+	if err != nil {
+		return err
+	}
+	err = f()
+	b.after(gen, err, err != nil && (ctx.Err() != nil || errors.Is(err, context.Canceled)))
+	return err
+}
+
+//before checks whether a call can go through, returning the generation it belongs to.
+func (b *Breaker) before() (uint64, error) {
+	b.mu.Lock()
+	from, to := b.refresh()
+	var err error
+	switch b.state {
+	case Open:
+		wait := b.settings.CoolDown - b.now().Sub(b.openedAt)
+		err = fmt.Errorf("%w: retry in %s", ErrCircuitOpen, wait.Round(time.Millisecond))
+	case HalfOpen:
+		if b.trials >= b.settings.HalfOpenRequests {
+			err = fmt.Errorf("%w: waiting for the trial requests", ErrCircuitOpen)
+		} else {
+			b.trials++
+		}
+	}
+	gen := b.generation
+	b.mu.Unlock()
+	b.notify(from, to)
+	return gen, err
+}
+
+//after records the outcome of a call of the given generation.
+//A neutral call only gives its trial slot back.
+func (b *Breaker) after(gen uint64, err error, neutral bool) {
+	b.mu.Lock()
+	from, to := b.state, b.state
+	if gen == b.generation && neutral {
+		if b.state == HalfOpen {
+			b.trials--
+		}
+	} else if gen == b.generation {
+		failed := b.settings.IsFailure(err)
+		switch b.state {
+		case Closed:
+			if !failed {
+				b.failures = 0
+			} else if b.failures++; b.failures >= b.settings.FailureThreshold {
+				b.setState(Open)
+			}
+		case HalfOpen:
+			if failed {
+				b.setState(Open)
+			} else if b.successes++; b.successes >= b.settings.HalfOpenRequests {
+				b.setState(Closed)
+			}
+		}
+		to = b.state
+	}
+	b.mu.Unlock()
+	b.notify(from, to)
+}
+
+//refresh moves an open circuit to half-open once the cool down is over. Must be called with the lock held.
+func (b *Breaker) refresh() (from, to State) {
+	from = b.state
+	if b.state == Open && b.now().Sub(b.openedAt) >= b.settings.CoolDown {
+		b.setState(HalfOpen)
+	}
+	return from, b.state
+}
+
+//setState changes the state and starts a new generation. Must be called with the lock held.
+func (b *Breaker) setState(s State) {
+	b.state = s
+	b.generation++
+	b.failures, b.trials, b.successes = 0, 0, 0
+	if s == Open {
+		b.openedAt = b.now()
+	}
+}
+
+func (b *Breaker) notify(from, to State) {
+	if from != to && b.settings.OnStateChange != nil {
+		b.settings.OnStateChange(from, to)
+	}
+}
+
+//Decorator wraps a gateway so that its calls go through the breaker.
+func (b *Breaker) Decorator(next data.AccountApiGateway) data.AccountApiGateway {
+	return &gateway{next: next, breaker: b}
+}
+
+type gateway struct {
+	next    data.AccountApiGateway
+	breaker *Breaker
+}
+
+func (g *gateway) Create(ctx context.Context, dto data.AccountDto) (acc data.AccountDto, err error) {
+	err = g.breaker.DoContext(ctx, func() error {
+		acc, err = g.next.Create(ctx, dto)
+		return err
+	})
+	return acc, err
+}
+
+func (g *gateway) Delete(ctx context.Context, uid uuid.UUID, vrs string) error {
+	return g.breaker.DoContext(ctx, func() error {
+		return g.next.Delete(ctx, uid, vrs)
+	})
+}
+
+func (g *gateway) Get(ctx context.Context, uid uuid.UUID) (acc data.AccountDto, err error) {
+	err = g.breaker.DoContext(ctx, func() error {
+		acc, err = g.next.Get(ctx, uid)
+		return err
+	})
+	return acc, err
+}
+
+func (g *gateway) Update(ctx context.Context, uid uuid.UUID, patch data.AccountPatchDto) (acc data.AccountDto, err error) {
+	err = g.breaker.DoContext(ctx, func() error {
+		acc, err = g.next.Update(ctx, uid, patch)
+		return err
+	})
+	return acc, err
+}
+
+func (g *gateway) List(ctx context.Context, params data.ListParams) (page data.AccountListDto, err error) {
+	err = g.breaker.DoContext(ctx, func() error {
+		page, err = g.next.List(ctx, params)
+		return err
+	})
+	return page, err
+}
@@ -0,0 +1,192 @@
+package breaker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	is2 "github.com/matryer/is"
+	form3 "github.com/petegabriel/form3_task"
+	"github.com/petegabriel/form3_task/data"
+	"github.com/petegabriel/form3_task/fake"
+)
+
+var errDown = errors.New("connection refused")
+
+//newBreaker returns a breaker with a clock moved by the test, and the state changes seen.
+func newBreaker(s Settings) (*Breaker, *time.Time, *[]string) {
+	now := time.Date(2021, 3, 25, 10, 0, 0, 0, time.UTC)
+	var changes []string
+	s.OnStateChange = func(from, to State) {
+		changes = append(changes, from.String()+" -> "+to.String())
+	}
+	b := New(s)
+	b.now = func() time.Time { return now }
+	return b, &now, &changes
+}
+
+func fail() error    { return errDown }
+func succeed() error { return nil }
+
+func TestBreakerOpensAndCloses(t *testing.T) {
+	is := is2.New(t)
+	b, now, changes := newBreaker(Settings{FailureThreshold: 3, CoolDown: time.Minute, HalfOpenRequests: 2})
+
+	//a success resets the count of consecutive failures
+	is.Equal(b.Do(fail), errDown)
+	is.Equal(b.Do(fail), errDown)
+	is.NoErr(b.Do(succeed))
+	is.Equal(b.Do(fail), errDown)
+	is.Equal(b.Do(fail), errDown)
+	is.Equal(b.State(), Closed)
+	is.Equal(b.Do(fail), errDown)
+	is.Equal(b.State(), Open)
+
+	called := false
+	err := b.Do(func() error { called = true; return nil })
+	is.True(errors.Is(err, ErrCircuitOpen))
+	is.True(!called)
+
+	*now = now.Add(time.Minute)
+	is.Equal(b.State(), HalfOpen)
+	is.NoErr(b.Do(succeed))
+	is.Equal(b.State(), HalfOpen) //needs two successful trials
+	is.NoErr(b.Do(succeed))
+	is.Equal(b.State(), Closed)
+
+	is.Equal(*changes, []string{"closed -> open", "open -> half-open", "half-open -> closed"})
+}
+
+func TestBreakerReopensOnFailedTrial(t *testing.T) {
+	is := is2.New(t)
+	b, now, changes := newBreaker(Settings{FailureThreshold: 1, CoolDown: time.Second})
+	is.Equal(b.Do(fail), errDown)
+
+	*now = now.Add(time.Second)
+	is.Equal(b.Do(fail), errDown)
+	is.Equal(b.State(), Open)
+	is.True(errors.Is(b.Do(succeed), ErrCircuitOpen))
+	is.Equal(*changes, []string{"closed -> open", "open -> half-open", "half-open -> open"})
+}
+
+func TestBreakerLimitsTrials(t *testing.T) {
+	is := is2.New(t)
+	b, now, _ := newBreaker(Settings{FailureThreshold: 1, CoolDown: time.Second})
+	is.Equal(b.Do(fail), errDown)
+	*now = now.Add(time.Second)
+
+	release := make(chan struct{})
+	done := make(chan error)
+	go func() {
+		done <- b.Do(func() error { <-release; return nil })
+	}()
+	for b.trialsStarted() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	//the single trial allowed is running
+	is.True(errors.Is(b.Do(succeed), ErrCircuitOpen))
+	close(release)
+	is.NoErr(<-done)
+	is.Equal(b.State(), Closed)
+}
+
+func TestCancelledTrialIsNeutral(t *testing.T) {
+	is := is2.New(t)
+	b, now, changes := newBreaker(Settings{FailureThreshold: 1, CoolDown: time.Second})
+	is.Equal(b.Do(fail), errDown)
+	*now = now.Add(time.Second)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	err := b.DoContext(ctx, func() error {
+		cancel()
+		return fmt.Errorf("get: %w", ctx.Err())
+	})
+	is.True(errors.Is(err, context.Canceled))
+	is.Equal(b.State(), HalfOpen) //the backend was not checked
+	is.Equal(b.trialsStarted(), 0)
+
+	//the caller's deadline is neutral too, even when the error does not say so
+	ctx, cancel = context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	err = b.DoContext(ctx, func() error {
+		<-ctx.Done()
+		return errDown
+	})
+	is.Equal(err, errDown)
+	is.Equal(b.State(), HalfOpen)
+
+	//the trial slot is free for a real trial
+	is.NoErr(b.Do(succeed))
+	is.Equal(b.State(), Closed)
+	is.Equal(*changes, []string{"closed -> open", "open -> half-open", "half-open -> closed"})
+}
+
+func (b *Breaker) trialsStarted() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.trials
+}
+
+func TestIsFailure(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{nil, false},
+		{errDown, true},
+		{fmt.Errorf("error sending get request: %w", context.DeadlineExceeded), true},
+		{context.Canceled, false},
+		{&data.APIError{StatusCode: 503}, true},
+		{&data.APIError{StatusCode: 429}, true},
+		{&data.APIError{StatusCode: 404, Err: data.ErrNotFound}, false},
+		{&data.APIError{StatusCode: 409, Err: data.ErrVersionConflict}, false},
+		{data.ErrRateLimited, false},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.err), func(t *testing.T) {
+			is := is2.New(t)
+			is.Equal(IsFailure(tt.err), tt.want)
+		})
+	}
+}
+
+func TestClientFailsFast(t *testing.T) {
+	is := is2.New(t)
+	var down int32 = 1
+	var requests int32
+	api := fake.NewHandler()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		if atomic.LoadInt32(&down) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		api.ServeHTTP(w, r)
+	}))
+	defer srv.Close()
+
+	b := New(Settings{FailureThreshold: 2, CoolDown: 20 * time.Millisecond})
+	client := form3.NewClient(form3.WithBaseURL(srv.URL+fake.AccountsPath), form3.WithDecorator(b.Decorator))
+	id := uuid.NewString()
+
+	for i := 0; i < 2; i++ {
+		_, err := client.GetAccount(id)
+		is.True(err != nil && !errors.Is(err, ErrCircuitOpen))
+	}
+	_, err := client.GetAccount(id)
+	is.True(errors.Is(err, ErrCircuitOpen))
+	is.Equal(atomic.LoadInt32(&requests), int32(2))
+
+	//not found is an answer of a healthy api, it closes the circuit
+	atomic.StoreInt32(&down, 0)
+	time.Sleep(20 * time.Millisecond)
+	_, err = client.GetAccount(id)
+	is.True(errors.Is(err, form3.ErrNotFound))
+	is.Equal(b.State(), Closed)
+}
@@ -0,0 +1,106 @@
+package form3_task
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/petegabriel/form3_task/iban"
+)
+
+//AccountBuilder builds accounts following the Form3 rules of their country: which
+//of bank_id, bic, account_number and iban are required or not supported, and their
+//format. The bank_id_code is set by the builder, and the base currency defaults to
+//the currency of the country.
+type AccountBuilder struct {
+	acc Account
+}
+
+//NewAccountBuilder starts building an account in the given country, one of the countries
+//supported by Form3 (AU, BE, CA, CH, DE, ES, FR, GB, GR, HK, IT, LU, NL, PL, PT and US).
+func NewAccountBuilder(country Country, id, orgId uuid.UUID, name ...string) *AccountBuilder {
+	b := &AccountBuilder{acc: *NewAccount(name, country, id, orgId)}
+	if rule, found := bankRules[country]; found {
+		b.acc.BankIdCode = rule.bankIdCode
+	}
+	if cur, found := country.DefaultCurrency(); found {
+		b.acc.BaseCurrency = cur
+	}
+	return b
+}
+
+//BankId sets the local bank identifier (e.g. the sort code in GB).
+func (b *AccountBuilder) BankId(id string) *AccountBuilder {
+	b.acc.BankId = id
+	return b
+}
+
+//Bic sets the SWIFT BIC of the bank.
+func (b *AccountBuilder) Bic(bic string) *AccountBuilder {
+	b.acc.Bic = bic
+	return b
+}
+
+//AccountNumber sets the account number. The api generates one when it is not set.
+func (b *AccountBuilder) AccountNumber(number string) *AccountBuilder {
+	b.acc.AccountNumber = number
+	return b
+}
+
+//Iban sets the IBAN, in countries using them. The api generates one when it is not set.
+func (b *AccountBuilder) Iban(iban string) *AccountBuilder {
+	b.acc.Iban = iban
+	return b
+}
+
+//BaseCurrency replaces the currency of the country as base currency of the account.
+func (b *AccountBuilder) BaseCurrency(c Currency) *AccountBuilder {
+	b.acc.BaseCurrency = c
+	return b
+}
+
+//Classification sets whether the account is Personal (the default) or Business.
+func (b *AccountBuilder) Classification(c Classification) *AccountBuilder {
+	b.acc.Classification = c
+	return b
+}
+
+//With changes any other attribute of the account.
+func (b *AccountBuilder) With(set func(acc *Account)) *AccountBuilder {
+	set(&b.acc)
+	return b
+}
+
+//Build checks the account against the rules of its country and returns a new Account.
+//Returns an error matching ErrCountryNotSupported when Form3 has no rules for the country,
+//or a *ValidationError listing every problem found.
+func (b *AccountBuilder) Build() (*Account, error) {
+	acc := b.acc
+	rule, found := bankRules[acc.Country]
+	if !found {
+		return nil, fmt.Errorf("%w: %q", ErrCountryNotSupported, acc.Country)
+	}
+
+	v := &validator{}
+	if rule.bankIdRequired && acc.BankId == "" {
+		v.add("bank_id", "required", fmt.Sprintf("is required in %s", acc.Country))
+	}
+	if rule.bicRequired && acc.Bic == "" {
+		v.add("bic", "required", fmt.Sprintf("is required in %s", acc.Country))
+	}
+	if acc.Iban != "" && rule.ibanSupported {
+		if err := iban.Validate(acc.Iban); err != nil {
+			v.add("iban", "format", strings.TrimPrefix(err.Error(), "iban: "))
+		} else if !strings.HasPrefix(strings.ToUpper(acc.Iban), string(acc.Country)) {
+			v.add("iban", "format", fmt.Sprintf("should be an IBAN of %s", acc.Country))
+		}
+	}
+
+	if err := acc.Validate(); err != nil {
+		v.fields = append(v.fields, err.(*ValidationError).Fields...)
+	}
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+	return &acc, nil
+}
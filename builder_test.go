@@ -0,0 +1,118 @@
+package form3_task
+
+import (
+	"errors"
+	"testing"
+
+	is2 "github.com/matryer/is"
+)
+
+func TestAccountBuilder(t *testing.T) {
+	tests := []struct {
+		country       Country
+		bankId        string
+		bic           string
+		accountNumber string
+		bankIdCode    string
+		currency      Currency
+	}{
+		{"AU", "", "ANZBAU3M", "1234567", "AUBSB", "AUD"},
+		{"BE", "539", "", "0075470", "BE", "EUR"},
+		{"CA", "", "ROYCCAT2", "1234567", "CACPA", "CAD"},
+		{"CH", "00762", "", "011623852957", "CHBCC", "CHF"},
+		{"DE", "37040044", "", "0532013", "DEBLZ", "EUR"},
+		{"ES", "21000418", "", "4502000513", "ESNCC", "EUR"},
+		{"FR", "2004101005", "", "0500013M02", "FR", "EUR"},
+		{"GB", "400300", "NWBKGB22", "41426819", "GBDSC", "GBP"},
+		{"GR", "0110125", "", "0000000012300695", "GRBIC", "EUR"},
+		{"HK", "", "HSBCHKHH", "123456789", "HKNCC", "HKD"},
+		{"IT", "0542811101", "", "000000123456", "ITNCC", "EUR"},
+		{"LU", "001", "", "9400644750000", "LULUX", "EUR"},
+		{"NL", "", "ABNANL2A", "0417164300", "", "EUR"},
+		{"PL", "10901014", "", "0000071219812874", "PLKNR", "PLN"},
+		{"PT", "00020123", "", "12345678901", "PTNCC", "EUR"},
+		{"US", "021000021", "CHASUS33", "123456789", "USABA", "USD"},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.country), func(t *testing.T) {
+			is := is2.New(t)
+			acc, err := NewAccountBuilder(tt.country, getRandomId(), getRandomId(), "Jane Doe").
+				BankId(tt.bankId).
+				Bic(tt.bic).
+				AccountNumber(tt.accountNumber).
+				Build()
+			is.NoErr(err)
+			is.Equal(acc.Country, tt.country)
+			is.Equal(acc.BankIdCode, tt.bankIdCode)
+			is.Equal(acc.BaseCurrency, tt.currency)
+			is.Equal(acc.AccountNumber, tt.accountNumber)
+		})
+	}
+}
+
+func TestAccountBuilderRejects(t *testing.T) {
+	tests := []struct {
+		name    string
+		builder *AccountBuilder
+		field   string
+		rule    string
+	}{
+		{"GB without bank id", NewAccountBuilder("GB", getRandomId(), getRandomId(), "Jane").Bic("NWBKGB22"), "bank_id", "required"},
+		{"GB without bic", NewAccountBuilder("GB", getRandomId(), getRandomId(), "Jane").BankId("400300"), "bic", "required"},
+		{"US without bic", NewAccountBuilder("US", getRandomId(), getRandomId(), "Jane").BankId("021000021"), "bic", "required"},
+		{"DE without bank id", NewAccountBuilder("DE", getRandomId(), getRandomId(), "Jane"), "bank_id", "required"},
+		{"AU without bic", NewAccountBuilder("AU", getRandomId(), getRandomId(), "Jane"), "bic", "required"},
+		{"NL with bank id", NewAccountBuilder("NL", getRandomId(), getRandomId(), "Jane").Bic("ABNANL2A").BankId("123"), "bank_id", "not_supported"},
+		{"FR with short bank id", NewAccountBuilder("FR", getRandomId(), getRandomId(), "Jane").BankId("20041"), "bank_id", "format"},
+		{"GB with long account number", NewAccountBuilder("GB", getRandomId(), getRandomId(), "Jane").BankId("400300").Bic("NWBKGB22").AccountNumber("414268190"), "account_number", "format"},
+		{"AU with iban", NewAccountBuilder("AU", getRandomId(), getRandomId(), "Jane").Bic("ANZBAU3M").Iban("GB29NWBK60161331926819"), "iban", "not_supported"},
+		{"GB with bad iban checksum", NewAccountBuilder("GB", getRandomId(), getRandomId(), "Jane").BankId("400300").Bic("NWBKGB22").Iban("GB11NWBK40030041426819"), "iban", "format"},
+		{"GB with german iban", NewAccountBuilder("GB", getRandomId(), getRandomId(), "Jane").BankId("400300").Bic("NWBKGB22").Iban("DE89370400440532013000"), "iban", "format"},
+		{"GB with foreign bank id code", NewAccountBuilder("GB", getRandomId(), getRandomId(), "Jane").BankId("400300").Bic("NWBKGB22").With(func(acc *Account) { acc.BankIdCode = "DEBLZ" }), "bank_id_code", "format"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			is := is2.New(t)
+			acc, err := tt.builder.Build()
+			is.True(acc == nil)
+			is.True(errors.Is(err, ErrValidation))
+			var valErr *ValidationError
+			is.True(errors.As(err, &valErr))
+			is.Equal(len(valErr.Fields), 1)
+			is.Equal(valErr.Fields[0].Field, tt.field)
+			is.Equal(valErr.Fields[0].Rule, tt.rule)
+		})
+	}
+}
+
+func TestAccountBuilderListsEveryProblem(t *testing.T) {
+	is := is2.New(t)
+	_, err := NewAccountBuilder("GB", getRandomId(), getRandomId(), "").Build()
+	var valErr *ValidationError
+	is.True(errors.As(err, &valErr))
+	fields := map[string]bool{}
+	for _, f := range valErr.Fields {
+		fields[f.Field] = true
+	}
+	is.Equal(fields, map[string]bool{"bank_id": true, "bic": true, "name.0": true})
+}
+
+func TestAccountBuilderValidIban(t *testing.T) {
+	is := is2.New(t)
+	acc, err := NewAccountBuilder("GB", getRandomId(), getRandomId(), "Jane").
+		BankId("601613").
+		Bic("NWBKGB22").
+		AccountNumber("31926819").
+		Iban("GB29NWBK60161331926819").
+		Classification(Business).
+		Build()
+	is.NoErr(err)
+	is.Equal(acc.Iban, "GB29NWBK60161331926819")
+	is.Equal(acc.Classification, Business)
+}
+
+func TestAccountBuilderUnsupportedCountry(t *testing.T) {
+	is := is2.New(t)
+	_, err := NewAccountBuilder("JP", getRandomId(), getRandomId(), "Jane").Build()
+	is.True(errors.Is(err, ErrCountryNotSupported))
+}
@@ -0,0 +1,197 @@
+//Package cache keeps the accounts fetched from the account api so that getting them
+//again does not take an http round trip. Changes made through the same client
+//(create, update, delete) update or invalidate the cached accounts; changes made
+//by anyone else are only seen once the cached account expires.
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"sync/atomic"
+
+	"github.com/google/uuid"
+	"github.com/petegabriel/form3_task/data"
+)
+
+//Cache caches the accounts got through the gateways it decorates. Plug it into a
+//client with form3_task.WithDecorator(c.Decorator). It is safe for concurrent use.
+type Cache struct {
+	store  Store
+	hits   uint64
+	misses uint64
+
+	//mu serializes the writes to the store, reads tracks the lookups in progress by key.
+	mu    sync.Mutex
+	reads map[string]*pending
+}
+
+//pending counts the lookups of an account in progress and the changes made to it
+//meanwhile. A lookup ending after a change keeps what it read out of the cache.
+type pending struct {
+	readers int
+	changes uint64
+}
+
+//Stats are the counters of a Cache.
+type Stats struct {
+
+	//Hits is the number of accounts found in the cache.
+	Hits uint64
+
+	//Misses is the number of accounts fetched from the account api.
+	Misses uint64
+}
+
+//HitRatio returns the fraction of the lookups found in the cache, 0 when there were none.
+func (s Stats) HitRatio() float64 {
+	if s.Hits+s.Misses == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(s.Hits+s.Misses)
+}
+
+//New creates a cache keeping the accounts in store. When store is nil,
+//an LRU of DefaultSize accounts kept for DefaultTTL is used.
+func New(store Store) *Cache {
+	if store == nil {
+		store = NewLRU(DefaultSize, DefaultTTL)
+	}
+	return &Cache{store: store, reads: map[string]*pending{}}
+}
+
+//Stats returns the hits and misses counted so far.
+func (c *Cache) Stats() Stats {
+	return Stats{Hits: atomic.LoadUint64(&c.hits), Misses: atomic.LoadUint64(&c.misses)}
+}
+
+//Decorator wraps a gateway so that Get is served from the cache when possible.
+func (c *Cache) Decorator(next data.AccountApiGateway) data.AccountApiGateway {
+	return &gateway{next: next, cache: c}
+}
+
+//get looks the account up. Values which cannot be decoded count as misses.
+func (c *Cache) get(ctx context.Context, key string) (data.AccountDto, bool) {
+	if value, found := c.store.Get(ctx, key); found {
+		dto := data.AccountDto{}
+		if err := json.Unmarshal(value, &dto); err == nil {
+			atomic.AddUint64(&c.hits, 1)
+			return dto, true
+		}
+		c.store.Delete(ctx, key)
+	}
+	atomic.AddUint64(&c.misses, 1)
+	return data.AccountDto{}, false
+}
+
+//fetch gets the account from the account api with get and caches it, unless the
+//account was changed through the cache meanwhile: what get read may then be out of date.
+func (c *Cache) fetch(ctx context.Context, key string, get func() (data.AccountDto, error)) (data.AccountDto, error) {
+	c.mu.Lock()
+	p := c.reads[key]
+	if p == nil {
+		p = &pending{}
+		c.reads[key] = p
+	}
+	p.readers++
+	changes := p.changes
+	c.mu.Unlock()
+
+	acc, err := get()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	p.readers--
+	if p.readers == 0 {
+		delete(c.reads, key)
+	}
+	switch {
+	case p.changes != changes:
+		//leave the cache as the change left it
+	case err == nil:
+		c.keep(ctx, acc)
+	case errors.Is(err, data.ErrNotFound):
+		c.store.Delete(ctx, key)
+	}
+	return acc, err
+}
+
+//changed records a change made to the account with the given id. The account as
+//changed is cached when known, otherwise the cached copy is dropped.
+func (c *Cache) changed(ctx context.Context, key string, acc *data.AccountDto) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if p := c.reads[key]; p != nil {
+		p.changes++
+	}
+	if acc == nil {
+		c.store.Delete(ctx, key)
+		return
+	}
+	c.keep(ctx, *acc)
+}
+
+//keep stores an encoded copy of the account, so later changes made by the caller do not
+//leak into the cache, unless a newer version is cached already. c.mu must be held.
+func (c *Cache) keep(ctx context.Context, dto data.AccountDto) {
+	if value, found := c.store.Get(ctx, dto.Data.ID); found {
+		cached := data.AccountDto{}
+		if err := json.Unmarshal(value, &cached); err == nil && cached.Data.Version > dto.Data.Version {
+			return
+		}
+	}
+	value, err := json.Marshal(dto)
+	if err != nil {
+		return
+	}
+	c.store.Set(ctx, dto.Data.ID, value)
+}
+
+//gateway serves Get from the cache and keeps it up to date with the changes made through it.
+type gateway struct {
+	next  data.AccountApiGateway
+	cache *Cache
+}
+
+func (g *gateway) Create(ctx context.Context, dto data.AccountDto) (data.AccountDto, error) {
+	acc, err := g.next.Create(ctx, dto)
+	if err != nil {
+		g.cache.changed(ctx, dto.Data.ID, nil)
+		return acc, err
+	}
+	g.cache.changed(ctx, acc.Data.ID, &acc)
+	return acc, nil
+}
+
+//Delete always invalidates the account: when it fails (e.g. with a version conflict)
+//the cached copy is likely out of date.
+func (g *gateway) Delete(ctx context.Context, uid uuid.UUID, vrs string) error {
+	err := g.next.Delete(ctx, uid, vrs)
+	g.cache.changed(ctx, uid.String(), nil)
+	return err
+}
+
+func (g *gateway) Get(ctx context.Context, uid uuid.UUID) (data.AccountDto, error) {
+	if dto, found := g.cache.get(ctx, uid.String()); found {
+		return dto, nil
+	}
+	return g.cache.fetch(ctx, uid.String(), func() (data.AccountDto, error) {
+		return g.next.Get(ctx, uid)
+	})
+}
+
+func (g *gateway) Update(ctx context.Context, uid uuid.UUID, patch data.AccountPatchDto) (data.AccountDto, error) {
+	acc, err := g.next.Update(ctx, uid, patch)
+	if err != nil {
+		g.cache.changed(ctx, uid.String(), nil)
+		return acc, err
+	}
+	g.cache.changed(ctx, uid.String(), &acc)
+	return acc, nil
+}
+
+//List is not cached, it always asks the account api.
+func (g *gateway) List(ctx context.Context, params data.ListParams) (data.AccountListDto, error) {
+	return g.next.List(ctx, params)
+}
@@ -0,0 +1,192 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/google/uuid"
+	is2 "github.com/matryer/is"
+	form3 "github.com/petegabriel/form3_task"
+	"github.com/petegabriel/form3_task/data"
+	"github.com/petegabriel/form3_task/fake"
+)
+
+//newClient returns a client caching the accounts of a fake account api,
+//and the number of GET requests the api received.
+func newClient(t *testing.T, c *Cache) (*form3.Client, *int32) {
+	var gets int32
+	api := fake.NewHandler()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			atomic.AddInt32(&gets, 1)
+		}
+		api.ServeHTTP(w, r)
+	}))
+	t.Cleanup(srv.Close)
+	return form3.NewClient(form3.WithBaseURL(srv.URL+fake.AccountsPath), form3.WithDecorator(c.Decorator)), &gets
+}
+
+func TestGetIsCached(t *testing.T) {
+	is := is2.New(t)
+	c := New(nil)
+	client, gets := newClient(t, c)
+	acc := form3.NewAccount([]string{"Jane Doe"}, "GB", uuid.New(), uuid.New())
+	_, err := client.CreateAccount(acc)
+	is.NoErr(err)
+
+	for i := 0; i < 3; i++ {
+		found, err := client.GetAccount(acc.Id.String())
+		is.NoErr(err)
+		is.Equal(found.Name, acc.Name)
+		found.Name[0] = "changed by the caller"
+	}
+
+	//the account sent back by create is cached
+	is.Equal(atomic.LoadInt32(gets), int32(0))
+	is.Equal(c.Stats(), Stats{Hits: 3})
+
+	_, err = client.GetAccount(uuid.NewString())
+	is.True(errors.Is(err, form3.ErrNotFound))
+	is.Equal(atomic.LoadInt32(gets), int32(1))
+	is.Equal(c.Stats(), Stats{Hits: 3, Misses: 1})
+	is.Equal(c.Stats().HitRatio(), 0.75)
+}
+
+func TestChangesInvalidateTheCache(t *testing.T) {
+	is := is2.New(t)
+	c := New(nil)
+	client, gets := newClient(t, c)
+	acc := form3.NewAccount([]string{"Jane Doe"}, "GB", uuid.New(), uuid.New())
+	_, err := client.CreateAccount(acc)
+	is.NoErr(err)
+
+	bic := "NWBKGB22"
+	updated, err := client.UpdateAccount(acc.Id.String(), 0, &form3.AccountPatch{Bic: &bic})
+	is.NoErr(err)
+	found, err := client.GetAccount(acc.Id.String())
+	is.NoErr(err)
+	is.Equal(found.Bic, bic)
+	is.Equal(found.Version, updated.Version)
+
+	//a failed update leaves the cache empty so the next get asks the api
+	_, err = client.UpdateAccount(acc.Id.String(), 0, &form3.AccountPatch{Bic: &bic})
+	is.True(errors.Is(err, form3.ErrVersionConflict))
+	_, err = client.GetAccount(acc.Id.String())
+	is.NoErr(err)
+	is.Equal(atomic.LoadInt32(gets), int32(1))
+
+	is.NoErr(client.DeleteAccount(acc.Id.String(), updated.Version))
+	_, err = client.GetAccount(acc.Id.String())
+	is.True(errors.Is(err, form3.ErrNotFound))
+	is.Equal(atomic.LoadInt32(gets), int32(2))
+}
+
+//brokenStore answers with values which are not accounts.
+type brokenStore struct{ deleted int }
+
+func (s *brokenStore) Get(context.Context, string) ([]byte, bool) { return []byte("{"), true }
+func (s *brokenStore) Set(context.Context, string, []byte)        {}
+func (s *brokenStore) Delete(context.Context, string)             { s.deleted++ }
+
+func TestUnreadableValuesAreMisses(t *testing.T) {
+	is := is2.New(t)
+	store := &brokenStore{}
+	c := New(store)
+	client, gets := newClient(t, c)
+	acc := form3.NewAccount([]string{"Jane Doe"}, "GB", uuid.New(), uuid.New())
+	_, err := client.CreateAccount(acc)
+	is.NoErr(err)
+
+	_, err = client.GetAccount(acc.Id.String())
+	is.NoErr(err)
+	is.Equal(atomic.LoadInt32(gets), int32(1))
+	is.Equal(c.Stats(), Stats{Misses: 1})
+	is.Equal(store.deleted, 1)
+}
+
+func TestConcurrentGets(t *testing.T) {
+	is := is2.New(t)
+	c := New(NewLRU(10, 0))
+	client, _ := newClient(t, c)
+	acc := form3.NewAccount([]string{"Jane Doe"}, "GB", uuid.New(), uuid.New())
+	_, err := client.CreateAccount(acc)
+	is.NoErr(err)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _ = client.GetAccount(acc.Id.String())
+		}()
+	}
+	wg.Wait()
+	is.Equal(c.Stats().Hits, uint64(20))
+}
+
+//slowGetGateway holds the answer of Get until released, once it has read the account.
+type slowGetGateway struct {
+	*fake.Gateway
+	read, release chan struct{}
+}
+
+func (g *slowGetGateway) Get(ctx context.Context, uid uuid.UUID) (data.AccountDto, error) {
+	acc, err := g.Gateway.Get(ctx, uid)
+	g.read <- struct{}{}
+	<-g.release
+	return acc, err
+}
+
+func TestGetEndingAfterUpdateDoesNotCacheOlderVersion(t *testing.T) {
+	is := is2.New(t)
+	ctx := context.Background()
+	c := New(nil)
+	slow := &slowGetGateway{Gateway: fake.NewGateway(), read: make(chan struct{}), release: make(chan struct{})}
+	gate := c.Decorator(slow)
+	id := uuid.New()
+	_, err := gate.Create(ctx, data.NewAccountDto(id, uuid.New(), "GB", []string{"Jane Doe"}))
+	is.NoErr(err)
+	c.store.Delete(ctx, id.String()) //expired
+
+	//a get reads version 0, then an update makes version 1 before the get ends
+	done := make(chan data.AccountDto)
+	go func() {
+		acc, _ := gate.Get(ctx, id)
+		done <- acc
+	}()
+	<-slow.read
+	bic := "NWBKGB22"
+	updated, err := gate.Update(ctx, id, data.AccountPatchDto{Data: data.PatchData{ID: id.String(), Attributes: data.AttributesPatch{Bic: &bic}}})
+	is.NoErr(err)
+	close(slow.release)
+	is.Equal((<-done).Data.Version, 0)
+
+	found, err := gate.Get(ctx, id)
+	is.NoErr(err)
+	is.Equal(found.Data.Version, updated.Data.Version) //served from the cache, not overwritten by the slow get
+	is.Equal(found.Data.Attributes.Bic, bic)
+	is.Equal(c.Stats().Hits, uint64(1))
+}
+
+func TestOlderVersionDoesNotReplaceNewer(t *testing.T) {
+	is := is2.New(t)
+	ctx := context.Background()
+	c := New(nil)
+	id := uuid.New()
+	newer := data.NewAccountDto(id, uuid.New(), "GB", []string{"Jane Doe"})
+	newer.Data.Version = 2
+	older := newer
+	older.Data.Version = 1
+
+	c.changed(ctx, id.String(), &newer)
+	c.changed(ctx, id.String(), &older)
+
+	found, ok := c.get(ctx, id.String())
+	is.True(ok)
+	is.Equal(found.Data.Version, 2)
+}
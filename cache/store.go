@@ -0,0 +1,115 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+//Store keeps the cached accounts, encoded, by account id. Implementations must be
+//safe for concurrent use. A failing store should report a miss, the account is
+//then fetched from the account api.
+type Store interface {
+
+	//Get returns the value stored under key, if any.
+	Get(ctx context.Context, key string) ([]byte, bool)
+
+	//Set stores value under key.
+	Set(ctx context.Context, key string, value []byte)
+
+	//Delete removes the value stored under key, if any.
+	Delete(ctx context.Context, key string)
+}
+
+//Defaults of the store created by New when none is given.
+const (
+	DefaultSize = 1000
+	DefaultTTL  = time.Minute
+)
+
+//LRU is an in-memory Store holding up to a number of values, for a limited time.
+//When full, the least recently used value is evicted.
+type LRU struct {
+	size  int
+	ttl   time.Duration
+	now   func() time.Time
+	mu    sync.Mutex
+	order *list.List //most recently used first
+	items map[string]*list.Element
+}
+
+type entry struct {
+	key     string
+	value   []byte
+	expires time.Time
+}
+
+//NewLRU creates a store holding up to size values (no limit when size <= 0),
+//each one for ttl (no expiry when ttl <= 0).
+func NewLRU(size int, ttl time.Duration) *LRU {
+	return &LRU{
+		size:  size,
+		ttl:   ttl,
+		now:   time.Now,
+		order: list.New(),
+		items: map[string]*list.Element{},
+	}
+}
+
+//Get returns the value stored under key, unless it has expired.
+func (s *LRU) Get(_ context.Context, key string) ([]byte, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	el, found := s.items[key]
+	if !found {
+		return nil, false
+	}
+	e := el.Value.(*entry)
+	if !e.expires.IsZero() && !s.now().Before(e.expires) {
+		s.remove(el)
+		return nil, false
+	}
+	s.order.MoveToFront(el)
+	return e.value, true
+}
+
+//Set stores value under key, evicting the least recently used value when the store is full.
+func (s *LRU) Set(_ context.Context, key string, value []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var expires time.Time
+	if s.ttl > 0 {
+		expires = s.now().Add(s.ttl)
+	}
+	if el, found := s.items[key]; found {
+		el.Value = &entry{key: key, value: value, expires: expires}
+		s.order.MoveToFront(el)
+		return
+	}
+	s.items[key] = s.order.PushFront(&entry{key: key, value: value, expires: expires})
+	if s.size > 0 && s.order.Len() > s.size {
+		s.remove(s.order.Back())
+	}
+}
+
+//Delete removes the value stored under key.
+func (s *LRU) Delete(_ context.Context, key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if el, found := s.items[key]; found {
+		s.remove(el)
+	}
+}
+
+//Len returns the number of values stored, expired ones included until they are looked up or evicted.
+func (s *LRU) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.order.Len()
+}
+
+func (s *LRU) remove(el *list.Element) {
+	s.order.Remove(el)
+	delete(s.items, el.Value.(*entry).key)
+}
@@ -0,0 +1,55 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	is2 "github.com/matryer/is"
+)
+
+func TestLRUEvictsLeastRecentlyUsed(t *testing.T) {
+	is := is2.New(t)
+	ctx := context.Background()
+	s := NewLRU(2, 0)
+	s.Set(ctx, "a", []byte("1"))
+	s.Set(ctx, "b", []byte("2"))
+	_, found := s.Get(ctx, "a") //b is now the least recently used
+	is.True(found)
+	s.Set(ctx, "c", []byte("3"))
+
+	_, found = s.Get(ctx, "b")
+	is.True(!found)
+	value, found := s.Get(ctx, "a")
+	is.True(found)
+	is.Equal(string(value), "1")
+	is.Equal(s.Len(), 2)
+
+	s.Set(ctx, "a", []byte("4"))
+	value, _ = s.Get(ctx, "a")
+	is.Equal(string(value), "4")
+	is.Equal(s.Len(), 2)
+
+	s.Delete(ctx, "a")
+	_, found = s.Get(ctx, "a")
+	is.True(!found)
+	is.Equal(s.Len(), 1)
+}
+
+func TestLRUExpiresValues(t *testing.T) {
+	is := is2.New(t)
+	ctx := context.Background()
+	now := time.Date(2021, 3, 25, 10, 0, 0, 0, time.UTC)
+	s := NewLRU(0, time.Minute)
+	s.now = func() time.Time { return now }
+
+	s.Set(ctx, "a", []byte("1"))
+	now = now.Add(59 * time.Second)
+	_, found := s.Get(ctx, "a")
+	is.True(found)
+
+	now = now.Add(time.Second)
+	_, found = s.Get(ctx, "a")
+	is.True(!found)
+	is.Equal(s.Len(), 0)
+}
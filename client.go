@@ -0,0 +1,340 @@
+package form3_task
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/petegabriel/form3_task/data"
+)
+
+//Logger receives the structured events emitted by Client for requests, responses,
+//retries and errors. Every event carries fields such as method, path, status,
+//account_id and duration. Use NewStdLogger to write them with a *log.Logger.
+type Logger = data.Logger
+
+//Level is the importance of a logged event.
+type Level = data.Level
+
+//Field is a named value attached to a logged event.
+type Field = data.Field
+
+//Levels of the logged events.
+const (
+	LevelDebug = data.LevelDebug
+	LevelInfo  = data.LevelInfo
+	LevelWarn  = data.LevelWarn
+	LevelError = data.LevelError
+)
+
+//NopLogger discards every event. It is the default logger.
+type NopLogger = data.NopLogger
+
+//NewStdLogger returns a Logger writing the events of the given level or above to out,
+//one per line (e.g. 'INFO received response method=GET path=/v1/organisation/accounts status=200').
+func NewStdLogger(out *log.Logger, min Level) Logger {
+	return data.NewStdLogger(out, min)
+}
+
+//RetryPolicy describes how requests failing with a transient error are retried.
+type RetryPolicy = data.RetryPolicy
+
+//DefaultRetryPolicy retries twice, with exponential backoff, the requests failing
+//due to the network or with status 429, 500, 502, 503 or 504.
+var DefaultRetryPolicy = data.DefaultRetryPolicy
+
+//Middleware intercepts the requests sent to the account api and the responses or
+//errors coming back. See WithMiddleware.
+type Middleware = data.Middleware
+
+//RoundTripperFunc turns a function into a http.RoundTripper, to write middlewares inline.
+type RoundTripperFunc = data.RoundTripperFunc
+
+//HeaderMiddleware sets the given headers on every request (e.g. an Authorization header).
+func HeaderMiddleware(h http.Header) Middleware {
+	return data.HeaderMiddleware(h)
+}
+
+//RequestIDMiddleware sends a X-Request-Id header with every request, taken from
+//the context (see ContextWithRequestID) or generated when there is none.
+func RequestIDMiddleware() Middleware {
+	return data.RequestIDMiddleware()
+}
+
+//ContextWithRequestID returns a copy of ctx carrying the request id sent
+//by RequestIDMiddleware with the requests made with it.
+func ContextWithRequestID(ctx context.Context, id string) context.Context {
+	return data.ContextWithRequestID(ctx, id)
+}
+
+//Authenticator adds the credentials expected by the account api to every request.
+//The auth package provides bearer token and http signature authenticators.
+type Authenticator = data.Authenticator
+
+//Decorator wraps the gateway of a Client to add behaviour around its calls. See WithDecorator.
+type Decorator = data.Decorator
+
+//RateLimit describes how many requests a client can send to the account api. See WithRateLimit.
+type RateLimit = data.RateLimit
+
+//Client gives access to the accounts resource of Form3 account api.
+//A Client is safe for concurrent use by multiple goroutines.
+type Client struct {
+	gate   data.AccountApiGateway
+	logger Logger
+}
+
+//Option customizes a Client built by NewClient.
+type Option func(*clientConfig)
+
+type clientConfig struct {
+	baseUrl    string
+	httpClient *http.Client
+	timeout    time.Duration
+	userAgent  string
+	retry      *RetryPolicy
+	rateLimit  *RateLimit
+	logger     Logger
+	mws        []Middleware
+	auth       Authenticator
+	gateway    data.AccountApiGateway
+	decorators []Decorator
+}
+
+//WithBaseURL sets the address of the accounts resource
+//(e.g. http://localhost:8080/v1/organisation/accounts).
+//By default the address is read from ACCOUNT_API_ADDR environment variable.
+func WithBaseURL(addr string) Option {
+	return func(c *clientConfig) {
+		c.baseUrl = addr
+	}
+}
+
+//WithHTTPClient sets the http.Client used to make requests to the account api.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *clientConfig) {
+		c.httpClient = hc
+	}
+}
+
+//WithTimeout sets a time limit for each request made to the account api.
+//The given http.Client, if any, is not modified.
+func WithTimeout(d time.Duration) Option {
+	return func(c *clientConfig) {
+		c.timeout = d
+	}
+}
+
+//WithUserAgent sets the User-Agent header sent with every request.
+func WithUserAgent(ua string) Option {
+	return func(c *clientConfig) {
+		c.userAgent = ua
+	}
+}
+
+//WithRetryPolicy sets the policy used to retry requests failing with a transient error
+//(network errors, 429 and 5xx status codes). By default requests are not retried.
+func WithRetryPolicy(p RetryPolicy) Option {
+	return func(c *clientConfig) {
+		c.retry = &p
+	}
+}
+
+//WithRateLimit limits the requests sent by the client to RequestsPerSecond, allowing bursts
+//of Burst requests. The limit is shared by every goroutine using the client and applies to
+//retries too. Requests over the limit wait for their turn, unless FailFast is set or the wait
+//would exceed the deadline of their context, and then fail with ErrRateLimited.
+func WithRateLimit(l RateLimit) Option {
+	return func(c *clientConfig) {
+		c.rateLimit = &l
+	}
+}
+
+//WithLogger sets the logger receiving the events of the client. By default nothing is logged.
+func WithLogger(l Logger) Option {
+	return func(c *clientConfig) {
+		c.logger = l
+	}
+}
+
+//WithMiddleware adds middlewares intercepting every request sent to the account api,
+//retries included. The first middleware is the outermost: it sees the request first
+//and the response last. Options can be repeated, later middlewares run after.
+func WithMiddleware(mws ...Middleware) Option {
+	return func(c *clientConfig) {
+		c.mws = append(c.mws, mws...)
+	}
+}
+
+//WithAuthenticator sets the authenticator adding credentials to every request, e.g.
+//auth.BearerToken or an auth.Signer. It runs after the middlewares, on every attempt.
+func WithAuthenticator(a Authenticator) Option {
+	return func(c *clientConfig) {
+		c.auth = a
+	}
+}
+
+//WithGateway replaces the gateway used to reach the account api.
+//When given, the options related to the http transport are ignored
+//and only the errors are logged, not the requests made by the gateway.
+func WithGateway(g data.AccountApiGateway) Option {
+	return func(c *clientConfig) {
+		c.gateway = g
+	}
+}
+
+//WithDecorator wraps the gateway used by the client, the given one included, to add
+//behaviour around every call (e.g. tracing, caching). The first decorator is the outermost
+//one. Options can be repeated, later decorators are wrapped by the previous ones.
+func WithDecorator(ds ...Decorator) Option {
+	return func(c *clientConfig) {
+		c.decorators = append(c.decorators, ds...)
+	}
+}
+
+//NewClient creates a new instance of Client customized by the given options.
+func NewClient(opts ...Option) *Client {
+	cfg := &clientConfig{logger: NopLogger{}}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	gate := cfg.gateway
+	if gate == nil {
+		gate = data.NewGateway(cfg.gatewayOptions()...)
+	}
+	for i := len(cfg.decorators) - 1; i >= 0; i-- {
+		gate = cfg.decorators[i](gate)
+	}
+	return &Client{
+		gate:   gate,
+		logger: cfg.logger,
+	}
+}
+
+func (c *clientConfig) gatewayOptions() []data.GatewayOption {
+	var opts []data.GatewayOption
+	if c.baseUrl != "" {
+		opts = append(opts, data.WithApiUrl(c.baseUrl))
+	}
+	if c.httpClient != nil || c.timeout > 0 {
+		hc := &http.Client{}
+		if c.httpClient != nil {
+			//copy it so the caller's client is left untouched
+			cp := *c.httpClient
+			hc = &cp
+		}
+		if c.timeout > 0 {
+			hc.Timeout = c.timeout
+		}
+		opts = append(opts, data.WithHttpClient(hc))
+	}
+	if c.userAgent != "" {
+		opts = append(opts, data.WithUserAgent(c.userAgent))
+	}
+	if c.retry != nil {
+		opts = append(opts, data.WithRetryPolicy(*c.retry))
+	}
+	if c.rateLimit != nil {
+		opts = append(opts, data.WithRateLimit(*c.rateLimit))
+	}
+	if len(c.mws) > 0 {
+		opts = append(opts, data.WithMiddleware(c.mws...))
+	}
+	if c.auth != nil {
+		opts = append(opts, data.WithAuthenticator(c.auth))
+	}
+	opts = append(opts, data.WithLogger(c.logger))
+	return opts
+}
+
+//CreateAccount creates a new account with the given info.
+//The account is validated first, no request is made when it is not valid.
+//Returns an error if a problem occurs while trying to create the new account.
+func (c *Client) CreateAccount(info *Account) (*Account, error) {
+	return c.CreateAccountContext(context.Background(), info)
+}
+
+//CreateAccountContext is like CreateAccount but the request is bound to ctx.
+func (c *Client) CreateAccountContext(ctx context.Context, info *Account) (*Account, error) {
+	acc, err := c.create(ctx, info)
+	if err != nil {
+		return nil, c.failed(ctx, "create account", info.Id.String(), err)
+	}
+	return c.decode(ctx, "create account", acc)
+}
+
+//create validates the account and sends it to the api, without logging failures.
+func (c *Client) create(ctx context.Context, info *Account) (data.AccountDto, error) {
+	if err := info.Validate(); err != nil {
+		return data.AccountDto{}, err
+	}
+	dto, err := info.ToDto()
+	if err != nil {
+		return data.AccountDto{}, err
+	}
+	return c.gate.Create(ctx, dto)
+}
+
+//DeleteAccount deletes the account with the given id and version.
+//Given id must be a valid uuid type.
+//Returns an error if a problem occurs while trying to delete the account with the given id.
+func (c *Client) DeleteAccount(id string, vrs int) error {
+	return c.DeleteAccountContext(context.Background(), id, vrs)
+}
+
+//DeleteAccountContext is like DeleteAccount but the request is bound to ctx.
+func (c *Client) DeleteAccountContext(ctx context.Context, id string, vrs int) error {
+	uid, isUuid := checkUuid(id)
+	if !isUuid {
+		return c.failed(ctx, "delete account", id, ErrInvalidID)
+	}
+
+	if err := c.gate.Delete(ctx, uid, strconv.Itoa(vrs)); err != nil {
+		return c.failed(ctx, "delete account", id, err)
+	}
+	return nil
+}
+
+//GetAccount retrieves an account by the given id.
+//Given id must be a valid uuid type.
+//Returns an error if a problem occurs while trying to get the account with the given id.
+func (c *Client) GetAccount(id string) (*Account, error) {
+	return c.GetAccountContext(context.Background(), id)
+}
+
+//GetAccountContext is like GetAccount but the request is bound to ctx.
+func (c *Client) GetAccountContext(ctx context.Context, id string) (*Account, error) {
+	uid, isUuid := checkUuid(id)
+	if !isUuid {
+		return nil, c.failed(ctx, "get account", id, ErrInvalidID)
+	}
+
+	found, err := c.gate.Get(ctx, uid)
+	if err != nil {
+		return nil, c.failed(ctx, "get account", id, err)
+	}
+	return c.decode(ctx, "get account", found)
+}
+
+//decode turns an account sent by the account api into an Account, reporting malformed content.
+func (c *Client) decode(ctx context.Context, op string, dto data.AccountDto) (*Account, error) {
+	acc, err := NewAccountFromDto(dto)
+	if err != nil {
+		return nil, c.failed(ctx, op, dto.Data.ID, err)
+	}
+	return acc, nil
+}
+
+//failed logs the error which made the operation fail and returns it.
+func (c *Client) failed(ctx context.Context, op, id string, err error) error {
+	fields := []Field{{Key: "operation", Value: op}}
+	if id != "" {
+		fields = append(fields, Field{Key: "account_id", Value: id})
+	}
+	fields = append(fields, Field{Key: "error", Value: err})
+	c.logger.Log(ctx, LevelError, op+" failed", fields...)
+	return err
+}
@@ -0,0 +1,288 @@
+package form3_task
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	is2 "github.com/matryer/is"
+	"github.com/petegabriel/form3_task/auth"
+	"github.com/petegabriel/form3_task/data"
+	"github.com/petegabriel/form3_task/fake"
+)
+
+func TestClientUsesGivenBaseUrlAndUserAgent(t *testing.T) {
+	is := is2.New(t)
+	id, orgId := getRandomId(), getRandomId()
+
+	var gotPath, gotAgent string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath, gotAgent = r.URL.Path, r.UserAgent()
+		w.WriteHeader(http.StatusOK)
+		_ = json.NewEncoder(w).Encode(data.NewAccountDto(id, orgId, "GB", []string{"Jane"}))
+	}))
+	defer srv.Close()
+
+	client := NewClient(
+		WithBaseURL(srv.URL+"/v1/organisation/accounts"),
+		WithHTTPClient(srv.Client()),
+		WithUserAgent("form3-test/1.0"),
+	)
+	acc, err := client.GetAccount(id.String())
+
+	is.NoErr(err)
+	is.Equal(acc.Id, id)
+	is.Equal(acc.OrganisationId, orgId)
+	is.Equal(gotPath, "/v1/organisation/accounts/"+id.String())
+	is.Equal(gotAgent, "form3-test/1.0")
+}
+
+func TestClientWithGateway(t *testing.T) {
+	is := is2.New(t)
+	id := getRandomId()
+	gate := &stubGateway{dto: data.NewAccountDto(id, getRandomId(), "PT", []string{"Pedro"})}
+
+	client := NewClient(WithGateway(gate))
+	acc, err := client.GetAccount(id.String())
+
+	is.NoErr(err)
+	is.Equal(acc.Id, id)
+	is.Equal(gate.getCalls, 1)
+}
+
+func TestClientGetAccountContextCancelled(t *testing.T) {
+	is := is2.New(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	client := NewClient(WithBaseURL(srv.URL))
+	acc, err := client.GetAccountContext(ctx, getRandomId().String())
+	is.True(errors.Is(err, context.DeadlineExceeded))
+	is.True(acc == nil)
+}
+
+func TestClientLogsEvents(t *testing.T) {
+	is := is2.New(t)
+	id := getRandomId()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	logger := &recordingLogger{}
+	client := NewClient(WithBaseURL(srv.URL+"/v1/organisation/accounts"), WithLogger(logger))
+	_, err := client.GetAccount(id.String())
+	is.True(errors.Is(err, ErrNotFound))
+
+	is.Equal(len(logger.events), 3)
+	is.Equal(logger.events[0].msg, "sending request")
+	is.Equal(logger.events[0].level, LevelDebug)
+	is.Equal(logger.events[1].msg, "received response")
+	is.Equal(logger.events[1].fields["method"], http.MethodGet)
+	is.Equal(logger.events[1].fields["path"], "/v1/organisation/accounts/"+id.String())
+	is.Equal(logger.events[1].fields["account_id"], id.String())
+	is.Equal(logger.events[1].fields["status"], http.StatusNotFound)
+	_, timed := logger.events[1].fields["duration"].(time.Duration)
+	is.True(timed)
+	is.Equal(logger.events[2].msg, "get account failed")
+	is.Equal(logger.events[2].level, LevelError)
+	is.Equal(logger.events[2].fields["error"], err)
+
+	logger.events = nil
+	_, err = client.GetAccount("not-an-id")
+	is.True(errors.Is(err, ErrInvalidID))
+	is.Equal(len(logger.events), 1)
+	is.Equal(logger.events[0].fields["account_id"], "not-an-id")
+}
+
+//recordingLogger keeps every event logged.
+type recordingLogger struct {
+	events []loggedEvent
+}
+
+type loggedEvent struct {
+	level  Level
+	msg    string
+	fields map[string]interface{}
+}
+
+func (l *recordingLogger) Log(ctx context.Context, level Level, msg string, fields ...Field) {
+	e := loggedEvent{level: level, msg: msg, fields: map[string]interface{}{}}
+	for _, f := range fields {
+		e.fields[f.Key] = f.Value
+	}
+	l.events = append(l.events, e)
+}
+
+//stubGateway is a minimal AccountApiGateway that always answers with the same account.
+type stubGateway struct {
+	dto         data.AccountDto
+	getCalls    int
+	createCalls int
+}
+
+
+func (s *stubGateway) Create(ctx context.Context, dto data.AccountDto) (data.AccountDto, error) {
+	s.createCalls++
+	return dto, nil
+}
+
+func (s *stubGateway) Delete(ctx context.Context, uid uuid.UUID, vrs string) error {
+	return nil
+}
+
+func (s *stubGateway) Get(ctx context.Context, id uuid.UUID) (data.AccountDto, error) {
+	s.getCalls++
+	return s.dto, nil
+}
+
+func (s *stubGateway) List(ctx context.Context, params data.ListParams) (data.AccountListDto, error) {
+	return data.AccountListDto{Data: []data.Data{s.dto.Data}}, nil
+}
+
+func (s *stubGateway) Update(ctx context.Context, uid uuid.UUID, patch data.AccountPatchDto) (data.AccountDto, error) {
+	return s.dto, nil
+}
+
+func TestClientMiddlewares(t *testing.T) {
+	is := is2.New(t)
+	id := getRandomId()
+	var gotAuth, gotRequestId string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth, gotRequestId = r.Header.Get("Authorization"), r.Header.Get(data.RequestIdHeader)
+		w.WriteHeader(http.StatusOK)
+		_ = json.NewEncoder(w).Encode(data.NewAccountDto(id, getRandomId(), "GB", []string{"Jane"}))
+	}))
+	defer srv.Close()
+
+	var statuses []int
+	record := func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			resp, err := next.RoundTrip(req)
+			if err == nil {
+				statuses = append(statuses, resp.StatusCode)
+			}
+			return resp, err
+		})
+	}
+	client := NewClient(
+		WithBaseURL(srv.URL),
+		WithMiddleware(HeaderMiddleware(http.Header{"Authorization": {"Bearer token"}}), RequestIDMiddleware()),
+		WithMiddleware(record),
+	)
+	_, err := client.GetAccountContext(ContextWithRequestID(context.Background(), "req-1"), id.String())
+
+	is.NoErr(err)
+	is.Equal(gotAuth, "Bearer token")
+	is.Equal(gotRequestId, "req-1")
+	is.Equal(statuses, []int{http.StatusOK})
+}
+
+func TestClientSignsRequests(t *testing.T) {
+	is := is2.New(t)
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	is.NoErr(err)
+	signer, err := auth.NewSigner("key-1", key)
+	is.NoErr(err)
+	verifier := auth.NewVerifier(map[string]crypto.PublicKey{"key-1": key.Public()})
+	srv := httptest.NewServer(verifier.Handler(fake.NewHandler()))
+	defer srv.Close()
+
+	client := NewClient(WithBaseURL(srv.URL+fake.AccountsPath), WithAuthenticator(signer), WithRetryPolicy(DefaultRetryPolicy))
+	created, err := client.CreateAccount(NewAccount([]string{"Jane Doe"}, "GB", getRandomId(), getRandomId()))
+	is.NoErr(err)
+	_, err = client.GetAccount(created.Id.String())
+	is.NoErr(err)
+	is.NoErr(client.DeleteAccount(created.Id.String(), created.Version))
+
+	//requests without a valid signature are rejected by the api
+	_, err = NewClient(WithBaseURL(srv.URL+fake.AccountsPath)).GetAccount(created.Id.String())
+	is.True(errors.Is(err, ErrUnauthorized))
+
+	//a failing authenticator stops the request before it is sent, without retrying it
+	var attempts int
+	count := func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			attempts++
+			return next.RoundTrip(req)
+		})
+	}
+	client = NewClient(WithBaseURL(srv.URL+fake.AccountsPath), WithRetryPolicy(DefaultRetryPolicy),
+		WithMiddleware(count), WithAuthenticator(auth.BearerToken("")))
+	_, err = client.GetAccount(created.Id.String())
+	is.True(errors.Is(err, ErrAuthentication))
+	is.True(errors.Is(err, auth.ErrMissingCredentials)) //the cause is kept
+	is.Equal(attempts, 1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	tokens := auth.TokenFunc(func(ctx context.Context) (string, error) {
+		return "", ctx.Err()
+	})
+	_, err = NewClient(WithBaseURL(srv.URL+fake.AccountsPath), WithAuthenticator(tokens)).GetAccountContext(ctx, created.Id.String())
+	is.True(errors.Is(err, ErrAuthentication))
+	is.True(errors.Is(err, context.Canceled))
+}
+
+//namedGateway records the order in which decorators see the calls.
+type namedGateway struct {
+	data.AccountApiGateway
+	name  string
+	trace *[]string
+}
+
+func (g *namedGateway) Get(ctx context.Context, id uuid.UUID) (data.AccountDto, error) {
+	*g.trace = append(*g.trace, g.name)
+	return g.AccountApiGateway.Get(ctx, id)
+}
+
+func TestClientDecorators(t *testing.T) {
+	is := is2.New(t)
+	id := getRandomId()
+	gate := &stubGateway{dto: data.NewAccountDto(id, getRandomId(), "GB", []string{"Jane"})}
+	var trace []string
+	named := func(name string) Decorator {
+		return func(next data.AccountApiGateway) data.AccountApiGateway {
+			return &namedGateway{AccountApiGateway: next, name: name, trace: &trace}
+		}
+	}
+
+	client := NewClient(WithGateway(gate), WithDecorator(named("outer"), named("middle")), WithDecorator(named("inner")))
+	_, err := client.GetAccount(id.String())
+
+	is.NoErr(err)
+	is.Equal(trace, []string{"outer", "middle", "inner"})
+	is.Equal(gate.getCalls, 1)
+}
+
+func TestClientRateLimit(t *testing.T) {
+	is := is2.New(t)
+	srv := fake.NewServer()
+	defer srv.Close()
+	client := NewClient(WithBaseURL(srv.AccountsURL()), WithRateLimit(RateLimit{RequestsPerSecond: 20, Burst: 2}))
+
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		_, err := client.GetAccount(getRandomId().String())
+		is.True(errors.Is(err, ErrNotFound))
+	}
+	is.True(time.Since(start) >= 90*time.Millisecond) //2 requests at once, then one every 50ms
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err := client.GetAccountContext(ctx, getRandomId().String())
+	is.True(errors.Is(err, ErrRateLimited))
+}
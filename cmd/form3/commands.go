@@ -0,0 +1,247 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	form3 "github.com/petegabriel/form3_task"
+)
+
+func (c *cli) newFlagSet(name, args string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(c.stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(c.stderr, "Usage: form3 %s [flags] %s\n\nFlags:\n", name, args)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+//parse parses the flags, which may come before or after the positional arguments.
+func parse(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			if err == flag.ErrHelp {
+				return nil, err
+			}
+			return nil, fmt.Errorf("%w: %s", errUsage, err)
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+//idArg returns the account id given either as the only positional argument or with --id.
+func idArg(positional []string, id string) (string, error) {
+	switch {
+	case len(positional) == 1 && id == "":
+		return positional[0], nil
+	case len(positional) == 0 && id != "":
+		return id, nil
+	default:
+		return "", fmt.Errorf("%w: expected a single account id", errUsage)
+	}
+}
+
+func (c *cli) create(args []string) error {
+	fs := c.newFlagSet("create", "")
+	common := &commonFlags{}
+	common.register(fs)
+	given := &accountInput{}
+	given.register(fs)
+	fs.Func("id", "id of the account (default a random uuid)", func(s string) error {
+		given.Id = &s
+		return nil
+	})
+	fs.Func("org-id", "id of the organisation", func(s string) error {
+		given.OrganisationId = &s
+		return nil
+	})
+	file := fs.String("file", "", "JSON or YAML file with the account attributes, '-' for stdin; flags take precedence")
+	positional, err := parse(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) > 0 {
+		return fmt.Errorf("%w: unexpected argument %q", errUsage, positional[0])
+	}
+
+	in := &accountInput{}
+	if *file != "" {
+		if in, err = c.load(*file); err != nil {
+			return err
+		}
+	}
+	in.merge(given)
+	acc, err := in.account()
+	if err != nil {
+		return err
+	}
+
+	s, err := c.newSession(common)
+	if err != nil {
+		return err
+	}
+	defer s.cancel()
+	created, err := s.client.CreateAccountContext(s.ctx, acc)
+	if err != nil {
+		return err
+	}
+	return s.out.account(c.stdout, created)
+}
+
+func (c *cli) get(args []string) error {
+	fs := c.newFlagSet("get", "<id>")
+	common := &commonFlags{}
+	common.register(fs)
+	id := fs.String("id", "", "id of the account")
+	positional, err := parse(fs, args)
+	if err != nil {
+		return err
+	}
+	uid, err := idArg(positional, *id)
+	if err != nil {
+		return err
+	}
+
+	s, err := c.newSession(common)
+	if err != nil {
+		return err
+	}
+	defer s.cancel()
+	acc, err := s.client.GetAccountContext(s.ctx, uid)
+	if err != nil {
+		return err
+	}
+	return s.out.account(c.stdout, acc)
+}
+
+func (c *cli) delete(args []string) error {
+	fs := c.newFlagSet("delete", "<id>")
+	common := &commonFlags{}
+	common.register(fs)
+	id := fs.String("id", "", "id of the account")
+	version := fs.Int("version", -1, "current version of the account (required)")
+	positional, err := parse(fs, args)
+	if err != nil {
+		return err
+	}
+	uid, err := idArg(positional, *id)
+	if err != nil {
+		return err
+	}
+	if *version < 0 {
+		return fmt.Errorf("%w: --version is required", errUsage)
+	}
+
+	s, err := c.newSession(common)
+	if err != nil {
+		return err
+	}
+	defer s.cancel()
+	if err = s.client.DeleteAccountContext(s.ctx, uid, *version); err != nil {
+		return err
+	}
+	fmt.Fprintf(c.stderr, "account %s deleted\n", uid)
+	return nil
+}
+
+func (c *cli) list(args []string) error {
+	fs := c.newFlagSet("list", "")
+	common := &commonFlags{}
+	common.register(fs)
+	opts := form3.ListOptions{Filter: map[string]string{}}
+	fs.IntVar(&opts.PageNumber, "page", 0, "zero based index of the page to print")
+	fs.IntVar(&opts.PageSize, "size", 0, "number of accounts per page (default as set by the api)")
+	fs.Func("filter", "attribute=value the accounts must match, e.g. country=GB (repeatable)", func(s string) error {
+		kv := strings.SplitN(s, "=", 2)
+		if len(kv) != 2 || kv[0] == "" {
+			return fmt.Errorf("filter must be in attribute=value format")
+		}
+		opts.Filter[kv[0]] = kv[1]
+		return nil
+	})
+	all := fs.Bool("all", false, "print the accounts of every page, starting at --page")
+	positional, err := parse(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) > 0 {
+		return fmt.Errorf("%w: unexpected argument %q", errUsage, positional[0])
+	}
+
+	s, err := c.newSession(common)
+	if err != nil {
+		return err
+	}
+	defer s.cancel()
+
+	var accounts []*form3.Account
+	if *all {
+		it := s.client.IterateAccounts(s.ctx, opts)
+		for it.Next() {
+			accounts = append(accounts, it.Account())
+		}
+		err = it.Err()
+	} else {
+		var page *form3.AccountPage
+		if page, err = s.client.ListAccountsContext(s.ctx, opts); err == nil {
+			accounts = page.Accounts
+		}
+	}
+	if err != nil {
+		return err
+	}
+	return s.out.accounts(c.stdout, accounts)
+}
+
+func (c *cli) update(args []string) error {
+	fs := c.newFlagSet("update", "<id>")
+	common := &commonFlags{}
+	common.register(fs)
+	id := fs.String("id", "", "id of the account")
+	version := fs.Int("version", -1, "current version of the account (required)")
+	given := &accountInput{}
+	given.register(fs)
+	file := fs.String("file", "", "JSON or YAML file with the attributes to change, '-' for stdin; flags take precedence")
+	positional, err := parse(fs, args)
+	if err != nil {
+		return err
+	}
+	uid, err := idArg(positional, *id)
+	if err != nil {
+		return err
+	}
+	if *version < 0 {
+		return fmt.Errorf("%w: --version is required", errUsage)
+	}
+
+	in := &accountInput{}
+	if *file != "" {
+		if in, err = c.load(*file); err != nil {
+			return err
+		}
+	}
+	in.merge(given)
+	patch, err := in.patch()
+	if err != nil {
+		return err
+	}
+
+	s, err := c.newSession(common)
+	if err != nil {
+		return err
+	}
+	defer s.cancel()
+	updated, err := s.client.UpdateAccountContext(s.ctx, uid, *version, patch)
+	if err != nil {
+		return err
+	}
+	return s.out.account(c.stdout, updated)
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	form3 "github.com/petegabriel/form3_task"
+	"gopkg.in/yaml.v3"
+)
+
+//config is the content of the config file. Every key is optional.
+type config struct {
+
+	//Addr is the address of the accounts resource.
+	Addr string `yaml:"addr"`
+
+	//Output is the default output format.
+	Output string `yaml:"output"`
+
+	//Timeout is the time limit of a command (e.g. '10s').
+	Timeout string `yaml:"timeout"`
+}
+
+//commonFlags are the flags accepted by every command.
+type commonFlags struct {
+	addr       string
+	configFile string
+	output     string
+	timeout    time.Duration
+	verbose    bool
+}
+
+const defaultTimeout = 30 * time.Second
+
+func (f *commonFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&f.addr, "addr", "", "address of the accounts resource (default $ACCOUNT_API_ADDR)")
+	fs.StringVar(&f.configFile, "config", "", "path of the config file (default $FORM3_CONFIG or $HOME/.config/form3/config.yaml)")
+	fs.StringVar(&f.output, "o", "", "output format: table, json or yaml (default table)")
+	fs.DurationVar(&f.timeout, "timeout", 0, "time limit of the command (default 30s)")
+	fs.BoolVar(&f.verbose, "v", false, "log the requests sent to the api to stderr")
+}
+
+//settings resolves the api address, output format and timeout out of
+//the flags, the environment and the config file, in this order.
+func (c *cli) settings(f *commonFlags) (addr string, out formatter, timeout time.Duration, err error) {
+	cfg, err := c.loadConfig(f.configFile)
+	if err != nil {
+		return "", nil, 0, err
+	}
+
+	addr = firstNonEmpty(f.addr, c.getenv("ACCOUNT_API_ADDR"), cfg.Addr)
+	if addr == "" {
+		return "", nil, 0, fmt.Errorf("%w: no api address, use --addr, ACCOUNT_API_ADDR or the config file", errUsage)
+	}
+
+	out, found := formatters[firstNonEmpty(f.output, cfg.Output, "table")]
+	if !found {
+		return "", nil, 0, fmt.Errorf("%w: output format must be one of table, json or yaml", errUsage)
+	}
+
+	timeout = f.timeout
+	if timeout == 0 && cfg.Timeout != "" {
+		if timeout, err = time.ParseDuration(cfg.Timeout); err != nil {
+			return "", nil, 0, fmt.Errorf("invalid timeout in config file: %w", err)
+		}
+	}
+	if timeout == 0 {
+		timeout = defaultTimeout
+	}
+	return addr, out, timeout, nil
+}
+
+//loadConfig reads the config file. A missing file is only an error when its path was given explicitly.
+func (c *cli) loadConfig(path string) (*config, error) {
+	explicit := true
+	if path == "" {
+		path = c.getenv("FORM3_CONFIG")
+	}
+	if path == "" {
+		explicit = false
+		home := c.getenv("HOME")
+		if home == "" {
+			return &config{}, nil
+		}
+		path = filepath.Join(home, ".config", "form3", "config.yaml")
+	}
+
+	cfg := &config{}
+	content, err := ioutil.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) && !explicit {
+		return cfg, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading config file: %w", err)
+	}
+	if err = yaml.Unmarshal(content, cfg); err != nil {
+		return nil, fmt.Errorf("error parsing config file %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+//session is what a command needs to talk to the account api.
+type session struct {
+	client *form3.Client
+	out    formatter
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+func (c *cli) newSession(f *commonFlags) (*session, error) {
+	addr, out, timeout, err := c.settings(f)
+	if err != nil {
+		return nil, err
+	}
+	opts := []form3.Option{
+		form3.WithBaseURL(addr),
+		form3.WithUserAgent("form3-cli"),
+		form3.WithRetryPolicy(form3.DefaultRetryPolicy),
+	}
+	if f.verbose {
+		logger := form3.NewStdLogger(log.New(c.stderr, "", log.LstdFlags), form3.LevelDebug)
+		opts = append(opts, form3.WithLogger(skipErrors{logger}))
+	}
+	client := form3.NewClient(opts...)
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	return &session{client: client, out: out, ctx: ctx, cancel: cancel}, nil
+}
+
+//skipErrors drops the error events, errors are printed by the command itself.
+type skipErrors struct {
+	form3.Logger
+}
+
+func (l skipErrors) Log(ctx context.Context, level form3.Level, msg string, fields ...form3.Field) {
+	if level < form3.LevelError {
+		l.Logger.Log(ctx, level, msg, fields...)
+	}
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
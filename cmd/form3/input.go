@@ -0,0 +1,228 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	form3 "github.com/petegabriel/form3_task"
+	"gopkg.in/yaml.v3"
+)
+
+//accountInput holds the account fields given in the command line or in a file.
+//Nil fields were not given.
+type accountInput struct {
+	Id                      *string  `json:"id" yaml:"id"`
+	OrganisationId          *string  `json:"organisation_id" yaml:"organisation_id"`
+	Country                 *string  `json:"country" yaml:"country"`
+	BaseCurrency            *string  `json:"base_currency" yaml:"base_currency"`
+	AccountNumber           *string  `json:"account_number" yaml:"account_number"`
+	BankId                  *string  `json:"bank_id" yaml:"bank_id"`
+	BankIdCode              *string  `json:"bank_id_code" yaml:"bank_id_code"`
+	Bic                     *string  `json:"bic" yaml:"bic"`
+	Iban                    *string  `json:"iban" yaml:"iban"`
+	Name                    []string `json:"name" yaml:"name"`
+	AlternativeNames        []string `json:"alternative_names" yaml:"alternative_names"`
+	Classification          *string  `json:"account_classification" yaml:"account_classification"`
+	JointAccount            *bool    `json:"joint_account" yaml:"joint_account"`
+	AccountMatchingOptOut   *bool    `json:"account_matching_opt_out" yaml:"account_matching_opt_out"`
+	SecondaryIdentification *string  `json:"secondary_identification" yaml:"secondary_identification"`
+	Switched                *bool    `json:"switched" yaml:"switched"`
+}
+
+//register binds the account attributes to flags of fs.
+func (in *accountInput) register(fs *flag.FlagSet) {
+	str := func(name, usage string, dst **string) {
+		fs.Func(name, usage, func(s string) error {
+			*dst = &s
+			return nil
+		})
+	}
+	list := func(name, usage string, dst *[]string) {
+		fs.Func(name, usage+" (repeat for each line)", func(s string) error {
+			*dst = append(*dst, s)
+			return nil
+		})
+	}
+	str("country", "ISO 3166-1 alpha-2 country code", &in.Country)
+	str("currency", "ISO 4217 base currency code", &in.BaseCurrency)
+	str("account-number", "account number", &in.AccountNumber)
+	str("bank-id", "local bank identifier", &in.BankId)
+	str("bank-id-code", "type of the bank identifier (e.g. GBDSC)", &in.BankIdCode)
+	str("bic", "SWIFT BIC in 8 or 11 characters format", &in.Bic)
+	str("iban", "IBAN of the account", &in.Iban)
+	list("name", "name of the account holder", &in.Name)
+	list("alt-name", "alternative name of the account", &in.AlternativeNames)
+	str("classification", "Personal or Business", &in.Classification)
+	fs.Var(&boolPtr{&in.JointAccount}, "joint", "the account is a joint account")
+	fs.Var(&boolPtr{&in.AccountMatchingOptOut}, "matching-opt-out", "the account opted out of account matching")
+	str("secondary-id", "secondary identification of the account", &in.SecondaryIdentification)
+	fs.Var(&boolPtr{&in.Switched}, "switched", "the account was switched away from the organisation")
+}
+
+//boolPtr is a boolean flag which keeps track of whether it was given.
+type boolPtr struct {
+	dst **bool
+}
+
+func (b *boolPtr) String() string {
+	if b.dst == nil || *b.dst == nil {
+		return ""
+	}
+	return strconv.FormatBool(**b.dst)
+}
+
+func (b *boolPtr) Set(s string) error {
+	v, err := strconv.ParseBool(s)
+	if err != nil {
+		return err
+	}
+	*b.dst = &v
+	return nil
+}
+
+func (b *boolPtr) IsBoolFlag() bool {
+	return true
+}
+
+//load reads the account fields from a JSON or YAML file ('-' reads stdin).
+//Files which name ends in '.json' are read as JSON, any other as YAML.
+func (c *cli) load(path string) (*accountInput, error) {
+	var content []byte
+	var err error
+	if path == "-" {
+		content, err = ioutil.ReadAll(c.stdin)
+	} else {
+		content, err = ioutil.ReadFile(path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading account file: %w", err)
+	}
+
+	in := &accountInput{}
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		dec := json.NewDecoder(bytes.NewReader(content))
+		dec.DisallowUnknownFields()
+		err = dec.Decode(in)
+	} else {
+		dec := yaml.NewDecoder(bytes.NewReader(content))
+		dec.KnownFields(true)
+		err = dec.Decode(in)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: error parsing account file %s: %s", errUsage, path, err)
+	}
+	return in, nil
+}
+
+//merge overrides the fields of in with those given in other.
+func (in *accountInput) merge(other *accountInput) {
+	pick := func(dst **string, src *string) {
+		if src != nil {
+			*dst = src
+		}
+	}
+	pickBool := func(dst **bool, src *bool) {
+		if src != nil {
+			*dst = src
+		}
+	}
+	pick(&in.Id, other.Id)
+	pick(&in.OrganisationId, other.OrganisationId)
+	pick(&in.Country, other.Country)
+	pick(&in.BaseCurrency, other.BaseCurrency)
+	pick(&in.AccountNumber, other.AccountNumber)
+	pick(&in.BankId, other.BankId)
+	pick(&in.BankIdCode, other.BankIdCode)
+	pick(&in.Bic, other.Bic)
+	pick(&in.Iban, other.Iban)
+	if other.Name != nil {
+		in.Name = other.Name
+	}
+	if other.AlternativeNames != nil {
+		in.AlternativeNames = other.AlternativeNames
+	}
+	pick(&in.Classification, other.Classification)
+	pickBool(&in.JointAccount, other.JointAccount)
+	pickBool(&in.AccountMatchingOptOut, other.AccountMatchingOptOut)
+	pick(&in.SecondaryIdentification, other.SecondaryIdentification)
+	pickBool(&in.Switched, other.Switched)
+}
+
+//account creates the account to register. A random id is used when none was given.
+func (in *accountInput) account() (*form3.Account, error) {
+	id := uuid.New()
+	if in.Id != nil {
+		var err error
+		if id, err = uuid.Parse(*in.Id); err != nil {
+			return nil, fmt.Errorf("%w: id", form3.ErrInvalidID)
+		}
+	}
+	if in.OrganisationId == nil {
+		return nil, fmt.Errorf("%w: an organisation id is required", errUsage)
+	}
+	orgId, err := uuid.Parse(*in.OrganisationId)
+	if err != nil {
+		return nil, fmt.Errorf("%w: organisation id", form3.ErrInvalidID)
+	}
+
+	acc := form3.NewAccount(in.Name, form3.Country(value(in.Country)), id, orgId)
+	acc.BaseCurrency = form3.Currency(value(in.BaseCurrency))
+	acc.AccountNumber = value(in.AccountNumber)
+	acc.BankId = value(in.BankId)
+	acc.BankIdCode = value(in.BankIdCode)
+	acc.Bic = value(in.Bic)
+	acc.Iban = value(in.Iban)
+	acc.AlternativeNames = in.AlternativeNames
+	if in.Classification != nil {
+		acc.Classification = form3.Classification(*in.Classification)
+	}
+	acc.IsJointAccount = in.JointAccount != nil && *in.JointAccount
+	acc.IsAccountMatchingOptOut = in.AccountMatchingOptOut != nil && *in.AccountMatchingOptOut
+	acc.SecondaryIdentification = value(in.SecondaryIdentification)
+	acc.IsSwitched = in.Switched != nil && *in.Switched
+	return acc, nil
+}
+
+//patch creates the patch with the attributes given. Id, organisation id and
+//country cannot be changed and are rejected.
+func (in *accountInput) patch() (*form3.AccountPatch, error) {
+	if in.Id != nil || in.OrganisationId != nil || in.Country != nil {
+		return nil, fmt.Errorf("%w: id, organisation_id and country cannot be updated", errUsage)
+	}
+	p := &form3.AccountPatch{
+		BaseCurrency:            (*form3.Currency)(in.BaseCurrency),
+		AccountNumber:           in.AccountNumber,
+		BankId:                  in.BankId,
+		BankIdCode:              in.BankIdCode,
+		Bic:                     in.Bic,
+		Iban:                    in.Iban,
+		Name:                    in.Name,
+		AlternativeNames:        in.AlternativeNames,
+		IsJointAccount:          in.JointAccount,
+		IsAccountMatchingOptOut: in.AccountMatchingOptOut,
+		SecondaryIdentification: in.SecondaryIdentification,
+		IsSwitched:              in.Switched,
+	}
+	if in.Classification != nil {
+		cls := form3.Classification(*in.Classification)
+		p.Classification = &cls
+	}
+	if p.IsEmpty() {
+		return nil, fmt.Errorf("%w: nothing to update", errUsage)
+	}
+	return p, nil
+}
+
+func value(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
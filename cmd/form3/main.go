@@ -0,0 +1,134 @@
+//Command form3 manages the accounts registered with Form3 account api.
+//
+//Usage:
+//
+//	form3 <command> [flags] [id]
+//
+//The commands are create, get, delete, list and update. Run 'form3 <command> -h'
+//to find out the flags of each command.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	form3 "github.com/petegabriel/form3_task"
+)
+
+//Exit codes of the command.
+const (
+	exitOK         = 0
+	exitError      = 1
+	exitUsage      = 2
+	exitNotFound   = 3
+	exitConflict   = 4
+	exitValidation = 5
+)
+
+const usage = `Usage: form3 <command> [flags] [id]
+
+Commands:
+  create   create an account out of flags or a JSON/YAML file
+  get      print an account
+  delete   delete an account
+  list     list accounts
+  update   change the attributes of an account
+
+The api address is taken from --addr, ACCOUNT_API_ADDR or the 'addr' key of the
+config file (--config, FORM3_CONFIG or $HOME/.config/form3/config.yaml).
+
+Exit codes: 0 ok, 1 error, 2 bad usage, 3 not found, 4 conflict, 5 validation error.
+`
+
+//errUsage is returned when the command line is not valid.
+var errUsage = errors.New("bad usage")
+
+//cli holds what the command needs from its environment, so it can be replaced in tests.
+type cli struct {
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+	getenv func(string) string
+}
+
+func main() {
+	c := &cli{stdin: os.Stdin, stdout: os.Stdout, stderr: os.Stderr, getenv: os.Getenv}
+	os.Exit(c.run(os.Args[1:]))
+}
+
+//run executes the command line and returns the exit code.
+func (c *cli) run(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprint(c.stderr, usage)
+		return exitUsage
+	}
+
+	commands := map[string]func([]string) error{
+		"create": c.create,
+		"get":    c.get,
+		"delete": c.delete,
+		"list":   c.list,
+		"update": c.update,
+	}
+	cmd, found := commands[args[0]]
+	switch {
+	case args[0] == "help" || args[0] == "-h" || args[0] == "--help":
+		fmt.Fprint(c.stdout, usage)
+		return exitOK
+	case !found:
+		fmt.Fprintf(c.stderr, "form3: unknown command %q\n\n%s", args[0], usage)
+		return exitUsage
+	}
+
+	err := cmd(args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		return exitOK
+	}
+	if err != nil {
+		c.printError(err)
+	}
+	return exitCode(err)
+}
+
+//exitCode maps an error to the exit code of the command.
+func exitCode(err error) int {
+	switch {
+	case err == nil:
+		return exitOK
+	case errors.Is(err, errUsage):
+		return exitUsage
+	case errors.Is(err, form3.ErrNotFound):
+		return exitNotFound
+	case errors.Is(err, form3.ErrDuplicate), errors.Is(err, form3.ErrVersionConflict):
+		return exitConflict
+	case errors.Is(err, form3.ErrValidation), errors.Is(err, form3.ErrInvalidID), errors.Is(err, form3.ErrEmptyPatch):
+		return exitValidation
+	default:
+		return exitError
+	}
+}
+
+//printError writes the error to stderr, listing each field error on its own line.
+func (c *cli) printError(err error) {
+	var fields []form3.FieldError
+	var valErr *form3.ValidationError
+	var apiErr *form3.APIError
+	switch {
+	case errors.As(err, &valErr):
+		fields = valErr.Fields
+	case errors.As(err, &apiErr):
+		fields = apiErr.Violations
+	}
+
+	if len(fields) == 0 {
+		fmt.Fprintf(c.stderr, "form3: %s\n", err)
+		return
+	}
+	fmt.Fprintf(c.stderr, "form3: %s\n", form3.ErrValidation)
+	for _, f := range fields {
+		fmt.Fprintf(c.stderr, "  %s\n", f.Error())
+	}
+}
@@ -0,0 +1,214 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	is2 "github.com/matryer/is"
+	"github.com/petegabriel/form3_task/fake"
+)
+
+//run executes the command line against a fake account api.
+func run(t *testing.T, env map[string]string, stdin string, args ...string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	c := &cli{
+		stdin:  strings.NewReader(stdin),
+		stdout: &stdout,
+		stderr: &stderr,
+		getenv: func(key string) string { return env[key] },
+	}
+	code := c.run(args)
+	return code, stdout.String(), stderr.String()
+}
+
+func newEnv(t *testing.T) map[string]string {
+	srv := fake.NewServer()
+	t.Cleanup(srv.Close)
+	return map[string]string{"ACCOUNT_API_ADDR": srv.AccountsURL()}
+}
+
+func createAccount(t *testing.T, env map[string]string, args ...string) accountView {
+	is := is2.New(t)
+	args = append([]string{"create", "-o", "json", "--org-id", uuid.NewString(), "--country", "GB", "--name", "Jane Doe"}, args...)
+	code, stdout, stderr := run(t, env, "", args...)
+	is.Equal(stderr, "")
+	is.Equal(code, exitOK)
+	view := accountView{}
+	is.NoErr(json.Unmarshal([]byte(stdout), &view))
+	return view
+}
+
+func TestCreateAndGet(t *testing.T) {
+	is := is2.New(t)
+	env := newEnv(t)
+	created := createAccount(t, env, "--bank-id", "400300", "--bank-id-code", "GBDSC", "--bic", "NWBKGB22", "--name", "Jane Marie Doe")
+	is.Equal(created.Country, "GB")
+	is.Equal(created.Name, []string{"Jane Doe", "Jane Marie Doe"})
+	is.Equal(created.BankId, "400300")
+
+	code, stdout, _ := run(t, env, "", "get", created.Id, "-o", "yaml")
+	is.Equal(code, exitOK)
+	is.True(strings.Contains(stdout, "id: "+created.Id))
+	is.True(strings.Contains(stdout, "bank_id_code: GBDSC"))
+
+	code, stdout, _ = run(t, env, "", "get", "--id", created.Id)
+	is.Equal(code, exitOK)
+	is.True(strings.Contains(stdout, "BANK ID CODE"))
+	is.True(strings.Contains(stdout, "GBDSC"))
+
+	code, _, stderr := run(t, env, "", "get", created.Id, "-v")
+	is.Equal(code, exitOK)
+	is.True(strings.Contains(stderr, "INFO received response method=GET"))
+
+	code, _, stderr = run(t, env, "", "get", uuid.NewString(), "-v")
+	is.Equal(code, exitNotFound)
+	is.Equal(strings.Count(stderr, "account not found"), 1) //printed by the command, not logged again
+}
+
+func TestCreateFromFile(t *testing.T) {
+	is := is2.New(t)
+	env := newEnv(t)
+	dir := t.TempDir()
+	id, orgId := uuid.NewString(), uuid.NewString()
+
+	yamlFile := filepath.Join(dir, "account.yaml")
+	is.NoErr(ioutil.WriteFile(yamlFile, []byte("organisation_id: "+orgId+"\ncountry: GB\nname:\n  - Samantha Holder\njoint_account: true\n"), 0600))
+	code, stdout, stderr := run(t, env, "", "create", "--file", yamlFile, "--id", id, "-o", "json")
+	is.Equal(stderr, "")
+	is.Equal(code, exitOK)
+	view := accountView{}
+	is.NoErr(json.Unmarshal([]byte(stdout), &view))
+	is.Equal(view.Id, id)
+	is.Equal(view.OrganisationId, orgId)
+	is.True(view.JointAccount)
+
+	//flags take precedence over the file
+	jsonFile := filepath.Join(dir, "account.json")
+	is.NoErr(ioutil.WriteFile(jsonFile, []byte(`{"organisation_id":"`+orgId+`","country":"FR","name":["Jean Dupont"]}`), 0600))
+	code, stdout, _ = run(t, env, "", "create", "--file", jsonFile, "--country", "GB", "-o", "json")
+	is.Equal(code, exitOK)
+	is.NoErr(json.Unmarshal([]byte(stdout), &view))
+	is.Equal(view.Country, "GB")
+	is.Equal(view.Name, []string{"Jean Dupont"})
+
+	code, _, _ = run(t, env, `{"organisation_id":"`+orgId+`","country":"GB","name":["Jean"]}`, "create", "--file", "-")
+	is.Equal(code, exitOK)
+}
+
+func TestUpdate(t *testing.T) {
+	is := is2.New(t)
+	env := newEnv(t)
+	created := createAccount(t, env)
+
+	code, stdout, stderr := run(t, env, "", "update", created.Id, "--version", "0", "--bic", "NWBKGB22", "--switched", "-o", "json")
+	is.Equal(stderr, "")
+	is.Equal(code, exitOK)
+	view := accountView{}
+	is.NoErr(json.Unmarshal([]byte(stdout), &view))
+	is.Equal(view.Bic, "NWBKGB22")
+	is.True(view.Switched)
+	is.Equal(view.Version, 1)
+
+	code, _, _ = run(t, env, "", "update", created.Id, "--version", "0", "--bic", "NWBKGB33")
+	is.Equal(code, exitConflict)
+
+	code, _, stderr = run(t, env, "", "update", created.Id, "--version", "1")
+	is.Equal(code, exitUsage)
+	is.True(strings.Contains(stderr, "nothing to update"))
+}
+
+func TestListAndDelete(t *testing.T) {
+	is := is2.New(t)
+	env := newEnv(t)
+	gb := createAccount(t, env)
+	createAccount(t, env, "--country", "FR")
+
+	code, stdout, _ := run(t, env, "", "list", "--filter", "country=GB", "-o", "json")
+	is.Equal(code, exitOK)
+	var views []accountView
+	is.NoErr(json.Unmarshal([]byte(stdout), &views))
+	is.Equal(len(views), 1)
+	is.Equal(views[0].Id, gb.Id)
+
+	code, stdout, _ = run(t, env, "", "list", "--size", "1", "--all")
+	is.Equal(code, exitOK)
+	is.Equal(strings.Count(strings.TrimSpace(stdout), "\n"), 2) //header and two accounts
+
+	code, _, _ = run(t, env, "", "delete", gb.Id, "--version", "0")
+	is.Equal(code, exitOK)
+	code, _, _ = run(t, env, "", "get", gb.Id)
+	is.Equal(code, exitNotFound)
+}
+
+func TestExitCodes(t *testing.T) {
+	env := newEnv(t)
+	orgId := uuid.NewString()
+	existing := createAccount(t, env)
+
+	tests := []struct {
+		name string
+		env  map[string]string
+		args []string
+		code int
+	}{
+		{"no command", env, nil, exitUsage},
+		{"unknown command", env, []string{"move"}, exitUsage},
+		{"help", env, []string{"get", "-h"}, exitOK},
+		{"unknown flag", env, []string{"get", "--colour"}, exitUsage},
+		{"missing id", env, []string{"get"}, exitUsage},
+		{"missing version", env, []string{"delete", existing.Id}, exitUsage},
+		{"missing address", map[string]string{}, []string{"get", existing.Id}, exitUsage},
+		{"unknown output", env, []string{"get", existing.Id, "-o", "xml"}, exitUsage},
+		{"not found", env, []string{"get", uuid.NewString()}, exitNotFound},
+		{"delete not found", env, []string{"delete", uuid.NewString(), "--version", "0"}, exitNotFound},
+		{"duplicate", env, []string{"create", "--id", existing.Id, "--org-id", orgId, "--country", "GB", "--name", "Jane"}, exitConflict},
+		{"version conflict", env, []string{"delete", existing.Id, "--version", "3"}, exitConflict},
+		{"invalid id", env, []string{"get", "not-an-id"}, exitValidation},
+		{"invalid account", env, []string{"create", "--org-id", orgId, "--country", "XX", "--name", ""}, exitValidation},
+		{"unreachable api", map[string]string{"ACCOUNT_API_ADDR": "http://127.0.0.1:1/v1/organisation/accounts"}, []string{"get", existing.Id, "--timeout", "1s"}, exitError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			is := is2.New(t)
+			code, _, _ := run(t, tt.env, "", tt.args...)
+			is.Equal(code, tt.code)
+		})
+	}
+}
+
+func TestValidationErrorsAreListed(t *testing.T) {
+	is := is2.New(t)
+	env := newEnv(t)
+	code, _, stderr := run(t, env, "", "create", "--org-id", uuid.NewString(), "--country", "XX", "--name", "")
+	is.Equal(code, exitValidation)
+	is.True(strings.Contains(stderr, "\n  country "))
+	is.True(strings.Contains(stderr, "\n  name.0 "))
+}
+
+func TestAddressFromConfig(t *testing.T) {
+	is := is2.New(t)
+	env := newEnv(t)
+	created := createAccount(t, env)
+
+	home := t.TempDir()
+	cfgDir := filepath.Join(home, ".config", "form3")
+	is.NoErr(os.MkdirAll(cfgDir, 0700))
+	is.NoErr(ioutil.WriteFile(filepath.Join(cfgDir, "config.yaml"), []byte("addr: "+env["ACCOUNT_API_ADDR"]+"\noutput: yaml\n"), 0600))
+
+	code, stdout, _ := run(t, map[string]string{"HOME": home}, "", "get", created.Id)
+	is.Equal(code, exitOK)
+	is.True(strings.HasPrefix(stdout, "id: "+created.Id))
+
+	//the flag wins over the environment, which wins over the config file
+	code, _, _ = run(t, map[string]string{"HOME": home, "ACCOUNT_API_ADDR": "http://127.0.0.1:1"}, "", "get", created.Id, "--addr", env["ACCOUNT_API_ADDR"])
+	is.Equal(code, exitOK)
+
+	code, _, _ = run(t, env, "", "get", created.Id, "--config", filepath.Join(home, "missing.yaml"))
+	is.Equal(code, exitError)
+}
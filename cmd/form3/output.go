@@ -0,0 +1,177 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	form3 "github.com/petegabriel/form3_task"
+	"gopkg.in/yaml.v3"
+)
+
+//formatter prints accounts in one of the output formats.
+type formatter interface {
+	account(w io.Writer, acc *form3.Account) error
+	accounts(w io.Writer, accs []*form3.Account) error
+}
+
+var formatters = map[string]formatter{
+	"table": tableFormat{},
+	"json":  jsonFormat{},
+	"yaml":  yamlFormat{},
+}
+
+//accountView is the representation of an account in JSON and YAML,
+//with the attributes named as in the account api.
+type accountView struct {
+	Id                      string   `json:"id" yaml:"id"`
+	OrganisationId          string   `json:"organisation_id" yaml:"organisation_id"`
+	Version                 int      `json:"version" yaml:"version"`
+	CreatedOn               string   `json:"created_on,omitempty" yaml:"created_on,omitempty"`
+	ModifiedOn              string   `json:"modified_on,omitempty" yaml:"modified_on,omitempty"`
+	Country                 string   `json:"country" yaml:"country"`
+	BaseCurrency            string   `json:"base_currency,omitempty" yaml:"base_currency,omitempty"`
+	AccountNumber           string   `json:"account_number,omitempty" yaml:"account_number,omitempty"`
+	BankId                  string   `json:"bank_id,omitempty" yaml:"bank_id,omitempty"`
+	BankIdCode              string   `json:"bank_id_code,omitempty" yaml:"bank_id_code,omitempty"`
+	Bic                     string   `json:"bic,omitempty" yaml:"bic,omitempty"`
+	Iban                    string   `json:"iban,omitempty" yaml:"iban,omitempty"`
+	Name                    []string `json:"name" yaml:"name"`
+	AlternativeNames        []string `json:"alternative_names,omitempty" yaml:"alternative_names,omitempty"`
+	Classification          string   `json:"account_classification,omitempty" yaml:"account_classification,omitempty"`
+	JointAccount            bool     `json:"joint_account" yaml:"joint_account"`
+	AccountMatchingOptOut   bool     `json:"account_matching_opt_out" yaml:"account_matching_opt_out"`
+	SecondaryIdentification string   `json:"secondary_identification,omitempty" yaml:"secondary_identification,omitempty"`
+	Switched                bool     `json:"switched" yaml:"switched"`
+	Status                  string   `json:"status,omitempty" yaml:"status,omitempty"`
+	StatusReason            string   `json:"status_reason,omitempty" yaml:"status_reason,omitempty"`
+	CustomerId              string   `json:"customer_id,omitempty" yaml:"customer_id,omitempty"`
+}
+
+func newAccountView(acc *form3.Account) accountView {
+	return accountView{
+		Id:                      acc.Id.String(),
+		OrganisationId:          acc.OrganisationId.String(),
+		Version:                 acc.Version,
+		CreatedOn:               formatTime(acc.CreatedOn),
+		ModifiedOn:              formatTime(acc.ModifiedOn),
+		Country:                 acc.Country.String(),
+		BaseCurrency:            acc.BaseCurrency.String(),
+		AccountNumber:           acc.AccountNumber,
+		BankId:                  acc.BankId,
+		BankIdCode:              acc.BankIdCode,
+		Bic:                     acc.Bic,
+		Iban:                    acc.Iban,
+		Name:                    acc.Name,
+		AlternativeNames:        acc.AlternativeNames,
+		Classification:          string(acc.Classification),
+		JointAccount:            acc.IsJointAccount,
+		AccountMatchingOptOut:   acc.IsAccountMatchingOptOut,
+		SecondaryIdentification: acc.SecondaryIdentification,
+		Switched:                acc.IsSwitched,
+		Status:                  string(acc.Status),
+		StatusReason:            acc.StatusReason,
+		CustomerId:              acc.CustomerId,
+	}
+}
+
+func newAccountViews(accs []*form3.Account) []accountView {
+	views := make([]accountView, 0, len(accs))
+	for _, acc := range accs {
+		views = append(views, newAccountView(acc))
+	}
+	return views
+}
+
+//formatTime prints a time in RFC 3339 format, or nothing for the zero time.
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+type jsonFormat struct{}
+
+func (jsonFormat) account(w io.Writer, acc *form3.Account) error {
+	return writeJson(w, newAccountView(acc))
+}
+
+func (jsonFormat) accounts(w io.Writer, accs []*form3.Account) error {
+	return writeJson(w, newAccountViews(accs))
+}
+
+func writeJson(w io.Writer, v interface{}) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+type yamlFormat struct{}
+
+func (yamlFormat) account(w io.Writer, acc *form3.Account) error {
+	return writeYaml(w, newAccountView(acc))
+}
+
+func (yamlFormat) accounts(w io.Writer, accs []*form3.Account) error {
+	return writeYaml(w, newAccountViews(accs))
+}
+
+func writeYaml(w io.Writer, v interface{}) error {
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(v); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+type tableFormat struct{}
+
+//account prints one attribute per line, leaving out the empty ones.
+func (tableFormat) account(w io.Writer, acc *form3.Account) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	row := func(key, value string) {
+		if value != "" {
+			fmt.Fprintf(tw, "%s\t%s\n", key, value)
+		}
+	}
+	row("ID", acc.Id.String())
+	row("ORGANISATION ID", acc.OrganisationId.String())
+	row("VERSION", strconv.Itoa(acc.Version))
+	row("CREATED ON", formatTime(acc.CreatedOn))
+	row("MODIFIED ON", formatTime(acc.ModifiedOn))
+	row("COUNTRY", acc.Country.String())
+	row("BASE CURRENCY", acc.BaseCurrency.String())
+	row("ACCOUNT NUMBER", acc.AccountNumber)
+	row("BANK ID", acc.BankId)
+	row("BANK ID CODE", acc.BankIdCode)
+	row("BIC", acc.Bic)
+	row("IBAN", acc.Iban)
+	row("NAME", strings.Join(acc.Name, ", "))
+	row("ALTERNATIVE NAMES", strings.Join(acc.AlternativeNames, ", "))
+	row("CLASSIFICATION", string(acc.Classification))
+	row("JOINT ACCOUNT", strconv.FormatBool(acc.IsJointAccount))
+	row("MATCHING OPT OUT", strconv.FormatBool(acc.IsAccountMatchingOptOut))
+	row("SECONDARY ID", acc.SecondaryIdentification)
+	row("SWITCHED", strconv.FormatBool(acc.IsSwitched))
+	row("STATUS", string(acc.Status))
+	row("STATUS REASON", acc.StatusReason)
+	row("CUSTOMER ID", acc.CustomerId)
+	return tw.Flush()
+}
+
+//accounts prints one account per line with its main attributes.
+func (tableFormat) accounts(w io.Writer, accs []*form3.Account) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tVERSION\tCOUNTRY\tBANK ID\tACCOUNT NUMBER\tIBAN\tNAME")
+	for _, acc := range accs {
+		fmt.Fprintf(tw, "%s\t%d\t%s\t%s\t%s\t%s\t%s\n", acc.Id, acc.Version, acc.Country,
+			acc.BankId, acc.AccountNumber, acc.Iban, strings.Join(acc.Name, ", "))
+	}
+	return tw.Flush()
+}
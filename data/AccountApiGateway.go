@@ -1,15 +1,29 @@
 package data
 
-import "github.com/google/uuid"
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
 
 type AccountApiGateway interface {
 
 	//Create a new account
-	Create(AccountDto) (AccountDto, error)
+	Create(ctx context.Context, dto AccountDto) (AccountDto, error)
 
 	//Delete an account by id
-	Delete(uid uuid.UUID, vrs string) error
+	Delete(ctx context.Context, uid uuid.UUID, vrs string) error
 
 	//Get an account by id
-	Get(id uuid.UUID) (AccountDto, error)
+	Get(ctx context.Context, id uuid.UUID) (AccountDto, error)
+
+	//Update some attributes of an account with the given version
+	Update(ctx context.Context, uid uuid.UUID, patch AccountPatchDto) (AccountDto, error)
+
+	//List a page of accounts
+	List(ctx context.Context, params ListParams) (AccountListDto, error)
 }
+
+//Decorator wraps an AccountApiGateway to add behaviour around its calls,
+//e.g. caching, tracing or a circuit breaker.
+type Decorator func(next AccountApiGateway) AccountApiGateway
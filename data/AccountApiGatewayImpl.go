@@ -2,8 +2,8 @@ package data
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"github.com/google/uuid"
 	"io"
@@ -12,6 +12,7 @@ import (
 	"net/url"
 	"os"
 	"strings"
+	"time"
 )
 
 //ContentType used to make http requests to the account api.
@@ -19,113 +20,353 @@ const ContentType = "application/vnd.api+json"
 
 //gateway represents the access point to fetch/modify data in account api.
 type gateway struct {
-	webClient http.Client
+	webClient *http.Client
 	apiUrl    string
+	userAgent string
+	retry     RetryPolicy
+	logger    Logger
+	mws       []Middleware
+	auth      Authenticator
+	limiter   *RateLimiter
+}
+
+//GatewayOption customizes the gateway built by NewGateway.
+type GatewayOption func(*gateway)
+
+//WithApiUrl sets the address of the accounts resource
+//(e.g. http://localhost:8080/v1/organisation/accounts) instead of
+//reading it from the ACCOUNT_API_ADDR environment variable.
+func WithApiUrl(addr string) GatewayOption {
+	return func(g *gateway) {
+		g.apiUrl = strings.TrimRight(addr, "/")
+	}
+}
+
+//WithHttpClient sets the http.Client used to reach the account api.
+func WithHttpClient(c *http.Client) GatewayOption {
+	return func(g *gateway) {
+		if c != nil {
+			g.webClient = c
+		}
+	}
+}
+
+//WithUserAgent sets the User-Agent header sent with every request.
+func WithUserAgent(ua string) GatewayOption {
+	return func(g *gateway) {
+		g.userAgent = ua
+	}
+}
+
+//WithRetryPolicy sets the policy used to retry requests failing with a transient error.
+//By default requests are not retried.
+func WithRetryPolicy(p RetryPolicy) GatewayOption {
+	return func(g *gateway) {
+		g.retry = p
+	}
+}
+
+//WithLogger sets the logger receiving an event for each request, response and retry.
+//By default nothing is logged.
+func WithLogger(l Logger) GatewayOption {
+	return func(g *gateway) {
+		if l != nil {
+			g.logger = l
+		}
+	}
+}
+
+//WithRateLimit limits the rate of the requests sent through the gateway, retries included.
+//The limit is shared by every goroutine using the gateway. By default there is no limit.
+func WithRateLimit(l RateLimit) GatewayOption {
+	return func(g *gateway) {
+		g.limiter = nil
+		if l.RequestsPerSecond > 0 {
+			g.limiter = NewRateLimiter(l)
+		}
+	}
+}
+
+//WithMiddleware adds middlewares intercepting every request sent to the account api.
+//They run in the order given, after those added by previous calls.
+func WithMiddleware(mws ...Middleware) GatewayOption {
+	return func(g *gateway) {
+		g.mws = append(g.mws, mws...)
+	}
 }
 
 //NewGateway creates a new instance of gateway which implements the contract
-//specified by AccountApiGateway interface.
-func NewGateway() AccountApiGateway {
-	return &gateway{
-		webClient: http.Client{},
-		apiUrl: os.Getenv("ACCOUNT_API_ADDR"),
+//specified by AccountApiGateway interface. Unless overridden by an option,
+//the api address is read from the ACCOUNT_API_ADDR environment variable.
+func NewGateway(opts ...GatewayOption) AccountApiGateway {
+	g := &gateway{
+		webClient: &http.Client{},
+		apiUrl:    os.Getenv("ACCOUNT_API_ADDR"),
+		logger:    NopLogger{},
+	}
+	for _, opt := range opts {
+		opt(g)
 	}
+	mws := g.mws
+	if g.auth != nil {
+		mws = append(mws[:len(mws):len(mws)], authMiddleware(g.auth))
+	}
+	if len(mws) > 0 {
+		//copy the client so the caller's one is left untouched
+		hc := *g.webClient
+		hc.Transport = Chain(hc.Transport, mws...)
+		g.webClient = &hc
+	}
+	return g
 }
 
 //Create a new account
-func (g *gateway) Create(dto AccountDto) (AccountDto, error) {
+func (g *gateway) Create(ctx context.Context, dto AccountDto) (AccountDto, error) {
 	cnt, err := json.Marshal(dto)
 	if err != nil {
-		return AccountDto{}, fmt.Errorf("error converting structure to json format: %s", err)
+		return AccountDto{}, fmt.Errorf("error converting structure to json format: %w", err)
 	}
-	resp, err := g.webClient.Post(g.apiUrl, ContentType, bytes.NewBuffer(cnt))
+	//the account id is chosen by the client so retrying cannot create a second account,
+	//the api rejects any account with an id already taken.
+	resp, attempts, err := g.send(ctx, http.MethodPost, g.apiUrl, nil, cnt, dto.Data.ID)
 	if err != nil {
-		return AccountDto{}, fmt.Errorf("error sending post request to account API: %s", err)
+		return AccountDto{}, err
 	}
 	defer resp.Body.Close()
 
 	switch resp.StatusCode {
 	case http.StatusCreated:
 		return handleGoodResult(resp.Body)
-	case http.StatusBadRequest, http.StatusConflict:
-		body, err := ioutil.ReadAll(resp.Body)
-		if err != nil {
-			return AccountDto{}, fmt.Errorf("error reading body content: %s", err)
-		}
-		accError := AccountError{}
-		if err = json.Unmarshal(body, &accError); err != nil {
-			return AccountDto{}, fmt.Errorf("error converting json format to structure: %s", err)
+	case http.StatusBadRequest:
+		return AccountDto{}, newAPIError(resp, ErrValidation)
+	case http.StatusConflict:
+		dupErr := newAPIError(resp, ErrDuplicate)
+		if attempts > 1 {
+			//an earlier attempt may have created the account before failing, the retry
+			//is then rejected because of that very account. Any other account with
+			//the same id is still a duplicate.
+			if uid, err := uuid.Parse(dto.Data.ID); err == nil {
+				if acc, err := g.Get(ctx, uid); err == nil {
+					if diff, err := DiffAccounts(dto, acc); err == nil && len(diff) == 0 {
+						return acc, nil
+					}
+				}
+			}
 		}
-		//grab error from api response
-		return AccountDto{}, errors.New(parseErrorMsg(accError.ErrorMsg))
+		return AccountDto{}, dupErr
 	default:
-		return AccountDto{}, fmt.Errorf("error creating account - code %d", resp.StatusCode)
+		return AccountDto{}, newAPIError(resp, nil)
 	}
 }
 
 //Delete an account by id and version
-func (g *gateway) Delete(uid uuid.UUID, vrs string) error {
+func (g *gateway) Delete(ctx context.Context, uid uuid.UUID, vrs string) error {
 	uri := fmt.Sprintf("%s/%s", g.apiUrl, uid.String())
-	req, err := http.NewRequest(http.MethodDelete, uri, nil)
-	if err != nil {
-		return err
-	}
 
 	//add 'version' param to query string
 	q := url.Values{
 		"version": []string{vrs},
 	}
-	req.URL.RawQuery = q.Encode()
-
-	resp, err := g.webClient.Do(req)
 
+	resp, attempts, err := g.send(ctx, http.MethodDelete, uri, q, nil, uid.String())
 	if err != nil {
-		return fmt.Errorf("error sending delete request to account API: %s", err)
+		return err
 	}
 	defer resp.Body.Close()
 
-
 	switch resp.StatusCode {
+	case http.StatusNoContent, http.StatusOK:
+		return nil
 	case http.StatusNotFound:
-		return fmt.Errorf("account with uuid %s not found", uid.String())
+		if attempts > 1 {
+			//an earlier attempt deleted the account before failing
+			return nil
+		}
+		return newAPIError(resp, ErrNotFound)
 	case http.StatusConflict:
-		return errors.New("account with specified version not found")
+		return newAPIError(resp, ErrVersionConflict)
 	default:
-		return nil
+		return newAPIError(resp, nil)
 	}
 }
 
 //Get an account by id
-func (g *gateway) Get(uid uuid.UUID) (AccountDto, error) {
-	resp, err := g.webClient.Get(fmt.Sprintf("%s/%s", g.apiUrl, uid.String()))
-
+func (g *gateway) Get(ctx context.Context, uid uuid.UUID) (AccountDto, error) {
+	resp, err := g.do(ctx, http.MethodGet, fmt.Sprintf("%s/%s", g.apiUrl, uid.String()), nil, nil, uid.String())
 	if err != nil {
-		return AccountDto{}, fmt.Errorf("error sending get request to account API: %s", err)
+		return AccountDto{}, err
 	}
 	defer resp.Body.Close()
 
-	switch resp.StatusCode{
+	switch resp.StatusCode {
 	case http.StatusNotFound:
-		return AccountDto{}, fmt.Errorf("account with uid %s not found", uid.String())
+		return AccountDto{}, newAPIError(resp, ErrNotFound)
 	case http.StatusOK:
 		return handleGoodResult(resp.Body)
 	default:
-		return AccountDto{}, fmt.Errorf("error getting account with uid %s - code %d", uid.String(), resp.StatusCode)
+		return AccountDto{}, newAPIError(resp, nil)
 	}
 }
 
-func handleGoodResult(src io.ReadCloser) (AccountDto, error) {
-	body, err := ioutil.ReadAll(src)
+//Update the attributes present in the patch of the account with the given id.
+//The patch must carry the current version of the account.
+func (g *gateway) Update(ctx context.Context, uid uuid.UUID, patch AccountPatchDto) (AccountDto, error) {
+	cnt, err := json.Marshal(patch)
+	if err != nil {
+		return AccountDto{}, fmt.Errorf("error converting structure to json format: %w", err)
+	}
+	resp, err := g.do(ctx, http.MethodPatch, fmt.Sprintf("%s/%s", g.apiUrl, uid.String()), nil, cnt, uid.String())
+	if err != nil {
+		return AccountDto{}, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return handleGoodResult(resp.Body)
+	case http.StatusBadRequest:
+		return AccountDto{}, newAPIError(resp, ErrValidation)
+	case http.StatusNotFound:
+		return AccountDto{}, newAPIError(resp, ErrNotFound)
+	case http.StatusConflict:
+		return AccountDto{}, newAPIError(resp, ErrVersionConflict)
+	default:
+		return AccountDto{}, newAPIError(resp, nil)
+	}
+}
+
+//List a page of accounts matching the given parameters
+func (g *gateway) List(ctx context.Context, params ListParams) (AccountListDto, error) {
+	resp, err := g.do(ctx, http.MethodGet, g.apiUrl, params.Query(), nil, "")
 	if err != nil {
-		return AccountDto{}, fmt.Errorf("error reading body content: %s", err)
+		return AccountListDto{}, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		page := AccountListDto{}
+		if err := decodeBody(resp.Body, &page); err != nil {
+			return AccountListDto{}, err
+		}
+		return page, nil
+	case http.StatusBadRequest:
+		return AccountListDto{}, newAPIError(resp, ErrValidation)
+	default:
+		return AccountListDto{}, newAPIError(resp, nil)
 	}
+}
+
+//do sends a request to the account api, retrying it according to the retry policy
+//while the failure is transient. The caller must close the body of the returned response.
+//accountId, when not empty, is only used to describe the request in the logged events.
+func (g *gateway) do(ctx context.Context, method, uri string, query url.Values, body []byte, accountId string) (*http.Response, error) {
+	resp, _, err := g.send(ctx, method, uri, query, body, accountId)
+	return resp, err
+}
+
+//send is do also reporting the number of attempts made.
+func (g *gateway) send(ctx context.Context, method, uri string, query url.Values, body []byte, accountId string) (*http.Response, int, error) {
+	for attempt := 1; ; attempt++ {
+		req, err := g.newRequest(ctx, method, uri, query, body)
+		if err != nil {
+			return nil, attempt, err
+		}
+		fields := []Field{{"method", method}, {"path", req.URL.Path}}
+		if accountId != "" {
+			fields = append(fields, Field{"account_id", accountId})
+		}
+		fields = append(fields, Field{"attempt", attempt})
+		if g.limiter != nil {
+			if err := g.limiter.Wait(ctx); err != nil {
+				err = fmt.Errorf("error sending %s request to account API: %w", strings.ToLower(method), err)
+				g.logger.Log(ctx, LevelError, "request not sent due to the rate limit", append(fields, Field{"error", err})...)
+				return nil, attempt, err
+			}
+		}
+		g.logger.Log(ctx, LevelDebug, "sending request", fields...)
+
+		start := time.Now()
+		resp, err := g.webClient.Do(req)
+		fields = append(fields, Field{"duration", time.Since(start)})
+		if err != nil {
+			err = fmt.Errorf("error sending %s request to account API: %w", strings.ToLower(method), err)
+		}
+
+		retry := g.retry.shouldRetry(ctx, method, attempt, resp, err)
+		var wait time.Duration
+		if retry {
+			wait, retry = g.retry.delay(ctx, attempt, resp)
+		}
+		switch {
+		case err != nil && !retry:
+			g.logger.Log(ctx, LevelError, "request failed", append(fields, Field{"error", err})...)
+			return nil, attempt, err
+		case !retry:
+			g.logger.Log(ctx, LevelInfo, "received response", append(fields, Field{"status", resp.StatusCode})...)
+			return resp, attempt, nil
+		}
+
+		if err != nil {
+			fields = append(fields, Field{"error", err})
+		} else {
+			fields = append(fields, Field{"status", resp.StatusCode})
+			//drain the body so the connection can be reused
+			_, _ = io.Copy(ioutil.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		g.logger.Log(ctx, LevelWarn, "retrying request", append(fields, Field{"delay", wait})...)
+		if err := sleep(ctx, wait); err != nil {
+			err = fmt.Errorf("error sending %s request to account API: %w", strings.ToLower(method), err)
+			g.logger.Log(ctx, LevelError, "request cancelled while waiting to retry", append(fields[:len(fields)-1], Field{"error", err})...)
+			return nil, attempt, err
+		}
+	}
+}
+
+//newRequest builds a request to the account api with the headers common to every call.
+//The request is bound to ctx so cancelling it aborts the call.
+func (g *gateway) newRequest(ctx context.Context, method, uri string, query url.Values, body []byte) (*http.Request, error) {
+	var rd io.Reader
+	if body != nil {
+		rd = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, uri, rd)
+	if err != nil {
+		return nil, err
+	}
+	if query != nil {
+		req.URL.RawQuery = query.Encode()
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", ContentType)
+	}
+	if g.userAgent != "" {
+		req.Header.Set("User-Agent", g.userAgent)
+	}
+	return req, nil
+}
+
+func handleGoodResult(src io.ReadCloser) (AccountDto, error) {
 	acc := AccountDto{}
-	if err = json.Unmarshal(body, &acc); err != nil {
-		return acc, fmt.Errorf("error converting json format to structure: %s", err)
+	if err := decodeBody(src, &acc); err != nil {
+		return AccountDto{}, err
 	}
 	return acc, nil
 }
 
+//decodeBody reads the whole body and converts its json content into v.
+func decodeBody(src io.Reader, v interface{}) error {
+	body, err := ioutil.ReadAll(src)
+	if err != nil {
+		return fmt.Errorf("error reading body content: %w", err)
+	}
+	if err = json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("error converting json format to structure: %w", err)
+	}
+	return nil
+}
+
 /*
 Error messages seem to come with different levels of
 context, for example:
@@ -1,10 +1,18 @@
 package data
 
 import (
-	"fmt"
+	"context"
+	"encoding/json"
+	"errors"
 	"github.com/google/uuid"
 	is2 "github.com/matryer/is"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestGet(t *testing.T){
@@ -13,7 +21,7 @@ func TestGet(t *testing.T){
 
 	gate := NewGateway()
 	id, _ := uuid.Parse(dto.Data.ID)
-	accFound, err := gate.Get(id)
+	accFound, err := gate.Get(context.Background(), id)
 
 	is.NoErr(err)
 	assertAccounts(is, accFound, dto)
@@ -26,9 +34,9 @@ func TestGetNotFoundID(t *testing.T){
 	gate := NewGateway()
 	var uid uuid.UUID
 
-	acc, err := gate.Get(uid)
+	acc, err := gate.Get(context.Background(), uid)
 	is.True(err != nil)
-	is.Equal(err.Error(), fmt.Sprintf("account with uid %s not found", uid.String()))
+	is.True(errors.Is(err, ErrNotFound))
 	is.Equal(acc, AccountDto{})
 }
 
@@ -37,7 +45,7 @@ func TestDelete(t *testing.T) {
 	gate := NewGateway()
 	dto := setupNewAccount([]string{"Kim", "Emma"})
 	uid, _ := uuid.Parse(dto.Data.ID)
-	err := gate.Delete(uid, "0")
+	err := gate.Delete(context.Background(), uid, "0")
 	is.NoErr(err)
 }
 
@@ -46,9 +54,9 @@ func TestDeleteNotFoundID(t *testing.T) {
 	gate := NewGateway()
 	var uid uuid.UUID
 
-	err := gate.Delete(uid, "0")
+	err := gate.Delete(context.Background(), uid, "0")
 	is.True(err != nil)
-	is.Equal(err.Error(), fmt.Sprintf("account with uuid %s not found", uid.String()))
+	is.True(errors.Is(err, ErrNotFound))
 }
 
 func TestDeleteConflictID(t *testing.T) {
@@ -56,9 +64,9 @@ func TestDeleteConflictID(t *testing.T) {
 	gate := NewGateway()
 	dto := setupNewAccount([]string{"Kim", "Emma"})
 	uid, _ := uuid.Parse(dto.Data.ID)
-	err := gate.Delete(uid, "1")
+	err := gate.Delete(context.Background(), uid, "1")
 	is.True(err != nil)
-	is.Equal(err.Error(), "account with specified version not found")
+	is.True(errors.Is(err, ErrVersionConflict))
 
 	resetState(uid)
 }
@@ -67,7 +75,7 @@ func TestCreate(t *testing.T) {
 	is := is2.New(t)
 	dto, _ := newAccount([]string{"Martin", "Fuchs"})
 	gate := NewGateway()
-	created, err := gate.Create(dto)
+	created, err := gate.Create(context.Background(), dto)
 	is.NoErr(err)
 	assertAccounts(is, created, dto)
 
@@ -80,11 +88,11 @@ func TestCreateWithConflict(t *testing.T) {
 	gate := NewGateway()
 
 	dto, _ := newAccount([]string{"Kim", "Emma", "First"})
-	_, err := gate.Create(dto)
+	_, err := gate.Create(context.Background(), dto)
 	is.NoErr(err)
-	_, err = gate.Create(dto)
+	_, err = gate.Create(context.Background(), dto)
 
-	is.True(err != nil)
+	is.True(errors.Is(err, ErrDuplicate))
 	//reuse the error message from account api, assert that the string is not empty. Content may vary
 	is.True(len(err.Error()) > 0)
 
@@ -97,19 +105,239 @@ func TestCreateWithBadRequest(t *testing.T)  {
 	gate := NewGateway()
 	//one example is empty line of name
 	dto, _ := newAccount([]string{"Kim", ""})
-	_, err := gate.Create(dto)
-	is.True(err != nil)
+	_, err := gate.Create(context.Background(), dto)
+	is.True(errors.Is(err, ErrValidation))
 	//reuse the error message from account api, assert that the string is not empty. Content may vary
 	is.True(len(err.Error()) > 0)
 }
 
 
+func TestCreateReportsEveryViolation(t *testing.T) {
+	is := is2.New(t)
+	gate := NewGateway()
+	dto, _ := newAccount([]string{"Kim", ""})
+	dto.Data.Attributes.Country = "gb"
+	_, err := gate.Create(context.Background(), dto)
+
+	var apiErr *APIError
+	is.True(errors.As(err, &apiErr))
+	rules := map[string]string{}
+	for _, v := range apiErr.Violations {
+		rules[v.Field] = v.Rule
+	}
+	is.Equal(rules["country"], "format")
+	is.Equal(rules["name.1"], "min_length")
+	is.True(strings.Contains(err.Error(), "country should match"))
+	is.True(strings.Contains(err.Error(), "name.1 should be at least 1 chars long"))
+}
+
+func TestParseViolations(t *testing.T) {
+	is := is2.New(t)
+	msg := "validation failure list:\nvalidation failure list:\n" +
+		"name.1 in body should be at least 1 chars long\n" +
+		"country in body should match '^[A-Z]{2}$'\n" +
+		"id in body must be of type uuid: \"abc\"\n" +
+		"name in body is required\n" +
+		"account_classification in body should be one of [Personal Business]\n" +
+		"alternative_names in body should have at most 3 items\n" +
+		"something unexpected happened\n"
+
+	is.Equal(ParseViolations(msg), []FieldError{
+		{Field: "name.1", Rule: "min_length", Message: "should be at least 1 chars long"},
+		{Field: "country", Rule: "format", Message: "should match '^[A-Z]{2}$'"},
+		{Field: "id", Rule: "type", Message: `must be of type uuid: "abc"`},
+		{Field: "name", Rule: "required", Message: "is required"},
+		{Field: "account_classification", Rule: "enum", Message: "should be one of [Personal Business]"},
+		{Field: "alternative_names", Rule: "max_items", Message: "should have at most 3 items"},
+	})
+	is.Equal(len(ParseViolations("Account cannot be created as it violates a duplicate constraint")), 0)
+}
+
+func TestAPIErrorListsViolations(t *testing.T) {
+	is := is2.New(t)
+	err := &APIError{
+		StatusCode: http.StatusBadRequest,
+		Err:        ErrValidation,
+		Violations: []FieldError{
+			{Field: "name.1", Rule: "min_length", Message: "should be at least 1 chars long"},
+			{Field: "country", Rule: "required", Message: "is required"},
+		},
+	}
+	is.Equal(err.Error(), "account data is not valid: name.1 should be at least 1 chars long; country is required")
+}
+
+func TestAPIErrorDetails(t *testing.T) {
+	is := is2.New(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(RequestIdHeader, "req-42")
+		w.WriteHeader(http.StatusConflict)
+		_, _ = w.Write([]byte(`{"error_message":"Account cannot be created as it violates a duplicate constraint","error_code":"DUP"}`))
+	}))
+	defer srv.Close()
+
+	gate := NewGateway(WithApiUrl(srv.URL))
+	dto, _ := newAccount([]string{"Kim"})
+	_, err := gate.Create(context.Background(), dto)
+
+	is.True(errors.Is(err, ErrDuplicate))
+	is.True(!errors.Is(err, ErrVersionConflict))
+	var apiErr *APIError
+	is.True(errors.As(err, &apiErr))
+	is.Equal(apiErr.StatusCode, http.StatusConflict)
+	is.Equal(apiErr.ErrorCode, "DUP")
+	is.Equal(apiErr.RequestID, "req-42")
+	is.Equal(apiErr.Message, "Account cannot be created as it violates a duplicate constraint")
+	is.Equal(err.Error(), "account already exists: Account cannot be created as it violates a duplicate constraint")
+}
+
+func TestDeleteErrorsByStatus(t *testing.T) {
+	tests := []struct {
+		status int
+		kind   error
+	}{
+		{http.StatusNoContent, nil},
+		{http.StatusNotFound, ErrNotFound},
+		{http.StatusConflict, ErrVersionConflict},
+		{http.StatusInternalServerError, nil},
+	}
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.status), func(t *testing.T) {
+			is := is2.New(t)
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+			}))
+			defer srv.Close()
+
+			err := NewGateway(WithApiUrl(srv.URL)).Delete(context.Background(), uuid.New(), "0")
+			if tt.status == http.StatusNoContent {
+				is.NoErr(err)
+				return
+			}
+			var apiErr *APIError
+			is.True(errors.As(err, &apiErr))
+			is.Equal(apiErr.StatusCode, tt.status)
+			is.Equal(apiErr.Err, tt.kind)
+		})
+	}
+}
+
+func TestGetMalformedBody(t *testing.T) {
+	is := is2.New(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"data": `))
+	}))
+	defer srv.Close()
+
+	_, err := NewGateway(WithApiUrl(srv.URL)).Get(context.Background(), uuid.New())
+	var syntaxErr *json.SyntaxError
+	is.True(errors.As(err, &syntaxErr))
+}
+
+func TestGetCancelledContext(t *testing.T) {
+	is := is2.New(t)
+	srv, aborted := newHangingServer()
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	gate := NewGateway(WithApiUrl(srv.URL))
+	_, err := gate.Get(ctx, uuid.New())
+	is.True(errors.Is(err, context.Canceled))
+	is.True(waitAborted(aborted)) //server should see the request being aborted
+}
+
+func TestCreateDeadlineExceeded(t *testing.T) {
+	is := is2.New(t)
+	srv, aborted := newHangingServer()
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	gate := NewGateway(WithApiUrl(srv.URL))
+	dto, _ := newAccount([]string{"Kim", "Emma"})
+	_, err := gate.Create(ctx, dto)
+	is.True(errors.Is(err, context.DeadlineExceeded))
+	is.True(waitAborted(aborted))
+}
+
+func TestDeleteCancelledBeforeSending(t *testing.T) {
+	is := is2.New(t)
+	srv, _ := newHangingServer()
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	gate := NewGateway(WithApiUrl(srv.URL))
+	err := gate.Delete(ctx, uuid.New(), "0")
+	is.True(errors.Is(err, context.Canceled))
+}
+
+func TestList(t *testing.T) {
+	is := is2.New(t)
+	first, _ := newAccount([]string{"Kim"})
+	second, _ := newAccount([]string{"Emma"})
+
+	var query url.Values
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+		_ = json.NewEncoder(w).Encode(AccountListDto{
+			Data:  []Data{first.Data, second.Data},
+			Links: Links{Next: "/v1/organisation/accounts?page%5Bnumber%5D=3"},
+		})
+	}))
+	defer srv.Close()
+
+	gate := NewGateway(WithApiUrl(srv.URL))
+	page, err := gate.List(context.Background(), ListParams{
+		PageNumber: 2,
+		PageSize:   2,
+		Filter:     map[string]string{"country": "GB"},
+	})
+
+	is.NoErr(err)
+	is.Equal(query.Get("page[number]"), "2")
+	is.Equal(query.Get("page[size]"), "2")
+	is.Equal(query.Get("filter[country]"), "GB")
+	is.Equal(len(page.Data), 2)
+	is.Equal(page.Data[0].ID, first.Data.ID)
+	is.Equal(page.Data[1].ID, second.Data.ID)
+	is.True(page.Links.Next != "")
+}
+
+//newHangingServer starts a server that never answers. The returned channel
+//is closed once the server notices the client went away.
+func newHangingServer() (*httptest.Server, chan struct{}) {
+	aborted := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		//the body must be consumed for the server to notice the connection is gone
+		_, _ = ioutil.ReadAll(r.Body)
+		select {
+		case <-r.Context().Done():
+			close(aborted)
+		case <-time.After(5 * time.Second):
+		}
+	}))
+	return srv, aborted
+}
+
+func waitAborted(aborted chan struct{}) bool {
+	select {
+	case <-aborted:
+		return true
+	case <-time.After(2 * time.Second):
+		return false
+	}
+}
+
 func setupNewAccount(name []string) AccountDto{
 	gate := NewGateway()
 
 	dto, err := newAccount(name)
 
-	acc, err := gate.Create(dto)
+	acc, err := gate.Create(context.Background(), dto)
 	if err != nil {
 		panic(err)
 	}
@@ -133,7 +361,7 @@ func newAccount(name []string) (AccountDto, error) {
 func resetState(uid uuid.UUID){
 	gate := NewGateway()
 	//reset state previously to testing
-	err := gate.Delete(uid, "0")
+	err := gate.Delete(context.Background(), uid, "0")
 	if err != nil {
 		panic(err)
 	}
@@ -0,0 +1,94 @@
+package data
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+//FieldDiff is an attribute which differs between the account sent and the one found.
+type FieldDiff struct {
+
+	//Field is the attribute as named by the account api (e.g. 'bank_id').
+	Field string
+
+	//Sent is the value sent, as decoded from json.
+	Sent interface{}
+
+	//Existing is the value of the account found, as decoded from json. Nil when not set.
+	Existing interface{}
+}
+
+func (d FieldDiff) String() string {
+	sent, _ := json.Marshal(d.Sent)
+	existing, _ := json.Marshal(d.Existing)
+	return fmt.Sprintf("%s: sent %s, found %s", d.Field, sent, existing)
+}
+
+//DiffAccounts compares the organisation id, relationships and attributes set in sent with
+//those of existing, in their api representation. Booleans are always compared, other
+//attributes only when not empty as the api may fill them (e.g. account_number, iban).
+//The differences are sorted by field name.
+func DiffAccounts(sent, existing AccountDto) ([]FieldDiff, error) {
+	sentFields, err := accountFields(sent)
+	if err != nil {
+		return nil, err
+	}
+	existingFields, err := accountFields(existing)
+	if err != nil {
+		return nil, err
+	}
+
+	var diff []FieldDiff
+	for field, value := range sentFields {
+		if isEmpty(value) {
+			continue
+		}
+		if found := existingFields[field]; !reflect.DeepEqual(value, found) {
+			diff = append(diff, FieldDiff{Field: field, Sent: value, Existing: found})
+		}
+	}
+	sort.Slice(diff, func(i, j int) bool { return diff[i].Field < diff[j].Field })
+	return diff, nil
+}
+
+//accountFields returns the organisation id, relationships and attributes of an account
+//as sent to the api, decoded from json.
+func accountFields(dto AccountDto) (map[string]interface{}, error) {
+	body, err := json.Marshal(dto.Data.Attributes)
+	if err != nil {
+		return nil, err
+	}
+	fields := map[string]interface{}{}
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return nil, err
+	}
+	fields["organisation_id"] = dto.Data.OrganisationID
+	if dto.Data.Relationships != nil {
+		body, err := json.Marshal(dto.Data.Relationships)
+		if err != nil {
+			return nil, err
+		}
+		var rel interface{}
+		if err := json.Unmarshal(body, &rel); err != nil {
+			return nil, err
+		}
+		fields["relationships"] = rel
+	}
+	return fields, nil
+}
+
+func isEmpty(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case string:
+		return v == ""
+	case []interface{}:
+		return len(v) == 0
+	case map[string]interface{}:
+		return len(v) == 0
+	}
+	return false
+}
@@ -1,9 +1,14 @@
 package data
 
-import "github.com/google/uuid"
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
 
 type AccountDto struct {
-	Data  Data  `json:"data"`
+	Data Data `json:"data"`
 }
 type Attributes struct {
 	Country                 string   `json:"country"`
@@ -20,27 +25,175 @@ type Attributes struct {
 	AccountMatchingOptOut   bool     `json:"account_matching_opt_out"`
 	SecondaryIdentification string   `json:"secondary_identification"`
 	Switched                bool     `json:"switched"`
+
+	Status                     string                      `json:"status,omitempty"`
+	StatusReason               string                      `json:"status_reason,omitempty"`
+	UserDefinedData            []UserDefinedData           `json:"user_defined_data,omitempty"`
+	ValidationType             string                      `json:"validation_type,omitempty"`
+	ReferenceMask              string                      `json:"reference_mask,omitempty"`
+	AcceptanceQualifier        string                      `json:"acceptance_qualifier,omitempty"`
+	ProcessingService          string                      `json:"processing_service,omitempty"`
+	UserDefinedInformation     string                      `json:"user_defined_information,omitempty"`
+	CustomerID                 string                      `json:"customer_id,omitempty"`
+	Title                      string                      `json:"title,omitempty"`
+	FirstName                  string                      `json:"first_name,omitempty"`
+	BankAccountName            string                      `json:"bank_account_name,omitempty"`
+	PrivateIdentification      *PrivateIdentification      `json:"private_identification,omitempty"`
+	OrganisationIdentification *OrganisationIdentification `json:"organisation_identification,omitempty"`
+}
+
+//UserDefinedData is a key/value pair stored with the account.
+type UserDefinedData struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+//PrivateIdentification identifies the person holding a personal account.
+type PrivateIdentification struct {
+	BirthDate      string   `json:"birth_date,omitempty"`
+	BirthCountry   string   `json:"birth_country,omitempty"`
+	Identification string   `json:"identification,omitempty"`
+	Address        []string `json:"address,omitempty"`
+	City           string   `json:"city,omitempty"`
+	Country        string   `json:"country,omitempty"`
+}
+
+//OrganisationIdentification identifies the organisation holding a business account.
+type OrganisationIdentification struct {
+	Identification string   `json:"identification,omitempty"`
+	Actors         []Actor  `json:"actors,omitempty"`
+	Address        []string `json:"address,omitempty"`
+	City           string   `json:"city,omitempty"`
+	Country        string   `json:"country,omitempty"`
+}
+
+//Actor is a person acting on behalf of an organisation.
+type Actor struct {
+	Name      []string `json:"name,omitempty"`
+	BirthDate string   `json:"birth_date,omitempty"`
+	Residency string   `json:"residency,omitempty"`
+}
+
+//Relationships links the account to other resources.
+type Relationships struct {
+	MasterAccount *RelationshipData `json:"master_account,omitempty"`
+	AccountEvents *RelationshipData `json:"account_events,omitempty"`
+}
+
+//RelationshipData lists the resources of a relationship.
+type RelationshipData struct {
+	Data []ResourceLink `json:"data"`
+}
+
+//ResourceLink identifies a resource of the api.
+type ResourceLink struct {
+	Type string `json:"type"`
+	ID   string `json:"id"`
 }
 type Data struct {
-	Type           string     `json:"type"`
-	ID             string     `json:"id"`
-	OrganisationID string     `json:"organisation_id"`
-	Version        int        `json:"version"`
-	Attributes     Attributes `json:"attributes"`
-	CreatedOn      string     `json:"created_on"`
-	ModifiedOn      string    `json:"modified_on"`
+	Type           string         `json:"type"`
+	ID             string         `json:"id"`
+	OrganisationID string         `json:"organisation_id"`
+	Version        int            `json:"version"`
+	Attributes     Attributes     `json:"attributes"`
+	Relationships  *Relationships `json:"relationships,omitempty"`
+	CreatedOn      string         `json:"created_on,omitempty"`
+	ModifiedOn     string         `json:"modified_on,omitempty"`
+}
+
+//ParseTimestamp reads a created_on or modified_on value sent by the account api,
+//an RFC 3339 timestamp. An empty value gives the zero time.
+func ParseTimestamp(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("not an RFC 3339 timestamp: %w", err)
+	}
+	return t, nil
 }
 
+//FormatTimestamp writes a time in the format used by the account api.
+//The zero time gives an empty value, so it is left out of the requests.
+func FormatTimestamp(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+//AccountListDto represents a page of accounts as returned by the account api.
+type AccountListDto struct {
+	Data  []Data `json:"data"`
+	Links Links  `json:"links"`
+}
+
+//Links holds the pagination links of a list of accounts.
+//A link is empty when there is no such page.
+type Links struct {
+	First string `json:"first"`
+	Last  string `json:"last"`
+	Next  string `json:"next"`
+	Prev  string `json:"prev"`
+	Self  string `json:"self"`
+}
+
+//AccountPatchDto represents the body of a request changing some attributes of an account.
+type AccountPatchDto struct {
+	Data PatchData `json:"data"`
+}
+
+//PatchData identifies the account and version being changed.
+type PatchData struct {
+	Type       string          `json:"type"`
+	ID         string          `json:"id"`
+	Version    int             `json:"version"`
+	Attributes AttributesPatch `json:"attributes"`
+}
+
+//AttributesPatch holds the attributes to change. Nil fields are left out of the request
+//and therefore keep their current value. Relationships cannot be changed once the account is created.
+type AttributesPatch struct {
+	BaseCurrency            *string  `json:"base_currency,omitempty"`
+	AccountNumber           *string  `json:"account_number,omitempty"`
+	BankID                  *string  `json:"bank_id,omitempty"`
+	BankIDCode              *string  `json:"bank_id_code,omitempty"`
+	Bic                     *string  `json:"bic,omitempty"`
+	Iban                    *string  `json:"iban,omitempty"`
+	Name                    []string `json:"name,omitempty"`
+	AlternativeNames        []string `json:"alternative_names,omitempty"`
+	AccountClassification   *string  `json:"account_classification,omitempty"`
+	JointAccount            *bool    `json:"joint_account,omitempty"`
+	AccountMatchingOptOut   *bool    `json:"account_matching_opt_out,omitempty"`
+	SecondaryIdentification *string  `json:"secondary_identification,omitempty"`
+	Switched                *bool    `json:"switched,omitempty"`
+
+	Status                     *string                     `json:"status,omitempty"`
+	StatusReason               *string                     `json:"status_reason,omitempty"`
+	UserDefinedData            []UserDefinedData           `json:"user_defined_data,omitempty"`
+	ValidationType             *string                     `json:"validation_type,omitempty"`
+	ReferenceMask              *string                     `json:"reference_mask,omitempty"`
+	AcceptanceQualifier        *string                     `json:"acceptance_qualifier,omitempty"`
+	ProcessingService          *string                     `json:"processing_service,omitempty"`
+	UserDefinedInformation     *string                     `json:"user_defined_information,omitempty"`
+	CustomerID                 *string                     `json:"customer_id,omitempty"`
+	Title                      *string                     `json:"title,omitempty"`
+	FirstName                  *string                     `json:"first_name,omitempty"`
+	BankAccountName            *string                     `json:"bank_account_name,omitempty"`
+	PrivateIdentification      *PrivateIdentification      `json:"private_identification,omitempty"`
+	OrganisationIdentification *OrganisationIdentification `json:"organisation_identification,omitempty"`
+}
 
 //NewAccountDto return a new account dto
 func NewAccountDto(id, orgId uuid.UUID, cty string, name []string) AccountDto {
 	return AccountDto{
-		Data:  Data{
+		Data: Data{
 			Type:           "accounts",
 			ID:             id.String(),
 			OrganisationID: orgId.String(),
 			Version:        0,
-			Attributes:     Attributes{
+			Attributes: Attributes{
 				Country:                 cty,
 				BaseCurrency:            "",
 				AccountNumber:           "",
@@ -58,4 +211,4 @@ func NewAccountDto(id, orgId uuid.UUID, cty string, name []string) AccountDto {
 			},
 		},
 	}
-}
\ No newline at end of file
+}
@@ -1,6 +1,37 @@
 package data
 
-import "fmt"
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+//RequestIdHeader is the response header carrying the id the account api assigned to a request.
+const RequestIdHeader = "X-Request-Id"
+
+var (
+	//ErrNotFound is reported when the requested account does not exist.
+	ErrNotFound = errors.New("account not found")
+
+	//ErrVersionConflict is reported when the given version does not match the account's current version.
+	ErrVersionConflict = errors.New("account with specified version not found")
+
+	//ErrDuplicate is reported when creating an account which id is already in use.
+	ErrDuplicate = errors.New("account already exists")
+
+	//ErrValidation is reported when the account api rejects the data sent.
+	ErrValidation = errors.New("account data is not valid")
+
+	//ErrUnauthorized is reported when the account api rejects the credentials of a request (401 or 403).
+	ErrUnauthorized = errors.New("request not authorized by the account api")
+
+	//ErrAuthentication is reported when the credentials of a request cannot be computed.
+	ErrAuthentication = errors.New("error authenticating request")
+)
 
 type AccountError struct {
 	ErrorMsg  string `json:"error_message"`
@@ -11,3 +42,144 @@ type AccountError struct {
 func (err AccountError) String() string {
 	return fmt.Sprintf("error '%s' with code '%s'", err.ErrorMsg, err.ErrorCode)
 }
+
+//APIError is returned when the account api answers with an unexpected status.
+//Use errors.Is with ErrNotFound, ErrVersionConflict, ErrDuplicate, ErrValidation or ErrUnauthorized
+//to find out what went wrong, or errors.As to inspect the details.
+type APIError struct {
+
+	//StatusCode is the http status of the response.
+	StatusCode int
+
+	//ErrorCode as sent by the account api, if any.
+	ErrorCode string
+
+	//Message is the full error_message sent by the account api, if any.
+	Message string
+
+	//Violations lists the fields the account api found not valid, parsed from the
+	//'validation failure list' in Message. Empty for other kinds of errors.
+	Violations []FieldError
+
+	//RequestID identifies the request in the account api, if the header was sent.
+	RequestID string
+
+	//Err is the sentinel error matching the status, or nil when there is none.
+	Err error
+}
+
+//Error returns the kind of error followed by every violation found by the account api,
+//or by the most relevant part of its message when there are none.
+func (e *APIError) Error() string {
+	var b strings.Builder
+	if e.Err != nil {
+		b.WriteString(e.Err.Error())
+	} else {
+		fmt.Fprintf(&b, "account api responded with status %d", e.StatusCode)
+	}
+	if len(e.Violations) > 0 {
+		b.WriteString(": ")
+		b.WriteString(joinFieldErrors(e.Violations))
+	} else if msg := parseErrorMsg(e.Message); msg != "" {
+		b.WriteString(": ")
+		b.WriteString(msg)
+	}
+	return b.String()
+}
+
+//Unwrap returns the sentinel error matching the status.
+func (e *APIError) Unwrap() error {
+	return e.Err
+}
+
+//newAPIError builds an APIError out of a response, reading its body
+//in search of the error details sent by the account api.
+func newAPIError(resp *http.Response, kind error) *APIError {
+	if kind == nil && (resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden) {
+		kind = ErrUnauthorized
+	}
+	apiErr := &APIError{
+		StatusCode: resp.StatusCode,
+		RequestID:  resp.Header.Get(RequestIdHeader),
+		Err:        kind,
+	}
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil || len(body) == 0 {
+		return apiErr
+	}
+	accError := AccountError{}
+	if err = json.Unmarshal(body, &accError); err != nil {
+		//not the usual error format, keep the raw content
+		apiErr.Message = string(body)
+		return apiErr
+	}
+	apiErr.Message = accError.ErrorMsg
+	apiErr.ErrorCode = accError.ErrorCode
+	apiErr.Violations = ParseViolations(accError.ErrorMsg)
+	return apiErr
+}
+
+//FieldError describes why a field of an account is not valid.
+type FieldError struct {
+
+	//Field is the path of the field as named by the account api (e.g. 'country', 'name.1').
+	Field string
+
+	//Rule is the name of the rule the field breaks (e.g. 'required', 'format').
+	Rule string
+
+	//Message explains the problem.
+	Message string
+}
+
+func (e FieldError) Error() string {
+	return fmt.Sprintf("%s %s", e.Field, e.Message)
+}
+
+//joinFieldErrors returns the messages of every field error in a single line.
+func joinFieldErrors(fields []FieldError) string {
+	msgs := make([]string, 0, len(fields))
+	for _, f := range fields {
+		msgs = append(msgs, f.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+//violationLine matches a line of the 'validation failure list', e.g. 'name.1 in body should be at least 1 chars long'.
+var violationLine = regexp.MustCompile(`^(\S+) in (?:body|query|path) (.+)$`)
+
+//violationRules maps the wording used by the account api to the name of the rule broken.
+var violationRules = []struct {
+	prefix string
+	rule   string
+}{
+	{"is required", "required"},
+	{"should be at least", "min_length"},
+	{"should be at most", "max_length"},
+	{"should have at least", "min_items"},
+	{"should have at most", "max_items"},
+	{"should match", "format"},
+	{"should be one of", "enum"},
+	{"must be of type", "type"},
+}
+
+//ParseViolations reads every line of a 'validation failure list' sent by the
+//account api, as found in APIError.Message. Lines which do not describe a field are ignored.
+func ParseViolations(msg string) []FieldError {
+	var fields []FieldError
+	for _, line := range strings.Split(msg, "\n") {
+		m := violationLine.FindStringSubmatch(strings.TrimSpace(line))
+		if m == nil {
+			continue
+		}
+		rule := "invalid"
+		for _, r := range violationRules {
+			if strings.HasPrefix(m[2], r.prefix) {
+				rule = r.rule
+				break
+			}
+		}
+		fields = append(fields, FieldError{Field: m[1], Rule: rule, Message: m[2]})
+	}
+	return fields
+}
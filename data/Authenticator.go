@@ -0,0 +1,55 @@
+package data
+
+import (
+	"fmt"
+	"net/http"
+)
+
+//Authenticator adds the credentials expected by the account api to a request,
+//e.g. an Authorization header. See the auth package for the bearer token and
+//http signature authenticators.
+type Authenticator interface {
+
+	//Authenticate changes req so that the account api accepts it. It is called on a copy
+	//of the request for every attempt, after the middlewares, so any signed header is final.
+	Authenticate(req *http.Request) error
+}
+
+//WithAuthenticator sets the authenticator of every request sent to the account api.
+func WithAuthenticator(a Authenticator) GatewayOption {
+	return func(g *gateway) {
+		g.auth = a
+	}
+}
+
+//authMiddleware authenticates the requests with a. It runs last so that nothing
+//changes the request once it is signed. Failures match both ErrAuthentication and the error of a.
+func authMiddleware(a Authenticator) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			req = req.Clone(req.Context())
+			if err := a.Authenticate(req); err != nil {
+				return nil, &authError{err: err}
+			}
+			return next.RoundTrip(req)
+		})
+	}
+}
+
+//authError is the failure of an Authenticator. It matches ErrAuthentication
+//and unwraps to the error of the authenticator.
+type authError struct {
+	err error
+}
+
+func (e *authError) Error() string {
+	return fmt.Sprintf("%s: %s", ErrAuthentication, e.err)
+}
+
+func (e *authError) Is(target error) bool {
+	return target == ErrAuthentication
+}
+
+func (e *authError) Unwrap() error {
+	return e.err
+}
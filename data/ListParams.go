@@ -0,0 +1,36 @@
+package data
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+)
+
+//ListParams holds the pagination and filtering parameters used to list accounts.
+type ListParams struct {
+
+	//PageNumber is the zero based index of the page to fetch.
+	PageNumber int
+
+	//PageSize is the number of accounts per page. The api default is used when zero.
+	PageSize int
+
+	//Filter maps an attribute (e.g. 'country', 'bank_id') to the value it must match.
+	Filter map[string]string
+}
+
+//Query encodes the parameters in the format expected by the account api,
+//e.g. page[number]=1&page[size]=20&filter[country]=GB
+func (p ListParams) Query() url.Values {
+	q := url.Values{}
+	if p.PageNumber > 0 {
+		q.Set("page[number]", strconv.Itoa(p.PageNumber))
+	}
+	if p.PageSize > 0 {
+		q.Set("page[size]", strconv.Itoa(p.PageSize))
+	}
+	for attr, value := range p.Filter {
+		q.Set(fmt.Sprintf("filter[%s]", attr), value)
+	}
+	return q
+}
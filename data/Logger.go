@@ -0,0 +1,84 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+)
+
+//Level is the importance of a logged event.
+type Level int
+
+const (
+	LevelDebug Level = iota - 1
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+func (l Level) String() string {
+	switch l {
+	case LevelDebug:
+		return "DEBUG"
+	case LevelInfo:
+		return "INFO"
+	case LevelWarn:
+		return "WARN"
+	case LevelError:
+		return "ERROR"
+	default:
+		return fmt.Sprintf("LEVEL(%d)", int(l))
+	}
+}
+
+//Field is a named value attached to a logged event (e.g. method, path, status, account_id, duration).
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
+//Logger receives the structured events emitted while talking to the account api.
+//Implementations must be safe for concurrent use.
+type Logger interface {
+	Log(ctx context.Context, level Level, msg string, fields ...Field)
+}
+
+//NopLogger discards every event. It is the default logger.
+type NopLogger struct{}
+
+//Log does nothing.
+func (NopLogger) Log(context.Context, Level, string, ...Field) {}
+
+//stdLogger writes events through a *log.Logger in a 'level msg key=value' format.
+type stdLogger struct {
+	out *log.Logger
+	min Level
+}
+
+//NewStdLogger returns a Logger writing the events of the given level or above
+//to out, one per line (e.g. 'INFO received response method=GET status=200').
+func NewStdLogger(out *log.Logger, min Level) Logger {
+	return &stdLogger{out: out, min: min}
+}
+
+func (l *stdLogger) Log(_ context.Context, level Level, msg string, fields ...Field) {
+	if level < l.min {
+		return
+	}
+	var b strings.Builder
+	b.WriteString(level.String())
+	b.WriteByte(' ')
+	b.WriteString(msg)
+	for _, f := range fields {
+		fmt.Fprintf(&b, " %s=%v", f.Key, quoteIfNeeded(fmt.Sprint(f.Value)))
+	}
+	l.out.Print(b.String())
+}
+
+func quoteIfNeeded(s string) string {
+	if s == "" || strings.ContainsAny(s, " \t\n\"=") {
+		return fmt.Sprintf("%q", s)
+	}
+	return s
+}
@@ -0,0 +1,86 @@
+package data
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	is2 "github.com/matryer/is"
+)
+
+func TestStdLogger(t *testing.T) {
+	is := is2.New(t)
+	var buf bytes.Buffer
+	logger := NewStdLogger(log.New(&buf, "", 0), LevelInfo)
+
+	logger.Log(context.Background(), LevelDebug, "sending request", Field{"method", "GET"})
+	logger.Log(context.Background(), LevelWarn, "retrying request",
+		Field{"method", "GET"}, Field{"status", 503}, Field{"error", errors.New("server is busy")}, Field{"id", ""})
+
+	is.Equal(buf.String(), "WARN retrying request method=GET status=503 error=\"server is busy\" id=\"\"\n")
+}
+
+func TestGatewayLogsRetries(t *testing.T) {
+	is := is2.New(t)
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	logger := &memoryLogger{}
+	policy := RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond, RetryableStatus: []int{http.StatusServiceUnavailable}}
+	gate := NewGateway(WithApiUrl(srv.URL), WithRetryPolicy(policy), WithLogger(logger))
+	dto, _ := newAccount([]string{"Kim"})
+	is.NoErr(gate.Delete(context.Background(), uuid.MustParse(dto.Data.ID), "0"))
+
+	is.Equal(logger.lines, []string{
+		"DEBUG sending request method=DELETE attempt=1",
+		"WARN retrying request method=DELETE attempt=1 status=503",
+		"DEBUG sending request method=DELETE attempt=2",
+		"INFO received response method=DELETE attempt=2 status=204",
+	})
+}
+
+func TestGatewayLogsTransportErrors(t *testing.T) {
+	is := is2.New(t)
+	logger := &memoryLogger{}
+	gate := NewGateway(WithApiUrl("http://127.0.0.1:1"), WithLogger(logger))
+	_, err := gate.List(context.Background(), ListParams{})
+	is.True(err != nil)
+	is.Equal(len(logger.lines), 2)
+	is.True(strings.HasPrefix(logger.lines[1], "ERROR request failed method=GET attempt=1"))
+}
+
+//memoryLogger keeps the level, message and a few fields of each event.
+type memoryLogger struct {
+	mu    sync.Mutex
+	lines []string
+}
+
+func (l *memoryLogger) Log(_ context.Context, level Level, msg string, fields ...Field) {
+	line := level.String() + " " + msg
+	for _, f := range fields {
+		switch f.Key {
+		case "method", "attempt", "status":
+			line += " " + f.Key + "=" + fmt.Sprint(f.Value)
+		}
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.lines = append(l.lines, line)
+}
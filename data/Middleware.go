@@ -0,0 +1,83 @@
+package data
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+//Middleware intercepts the requests sent to the account api. It wraps the next
+//http.RoundTripper of the chain and can change the outgoing request (on a clone, as
+//required by http.RoundTripper) and see the response or the error coming back.
+//Every attempt of a retried request goes through the middlewares.
+type Middleware func(next http.RoundTripper) http.RoundTripper
+
+//RoundTripperFunc turns a function into a http.RoundTripper, to write middlewares inline.
+type RoundTripperFunc func(req *http.Request) (*http.Response, error)
+
+//RoundTrip calls f(req).
+func (f RoundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+//Chain wraps rt with the middlewares. The first middleware is the outermost one: it sees
+//the request first and the response last. A nil rt stands for http.DefaultTransport.
+func Chain(rt http.RoundTripper, mws ...Middleware) http.RoundTripper {
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+	for i := len(mws) - 1; i >= 0; i-- {
+		rt = mws[i](rt)
+	}
+	return rt
+}
+
+//HeaderMiddleware sets the given headers on every request, replacing the values
+//set by the gateway or by previous middlewares.
+func HeaderMiddleware(h http.Header) Middleware {
+	h = h.Clone()
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			req = req.Clone(req.Context())
+			for key, values := range h {
+				req.Header[http.CanonicalHeaderKey(key)] = append([]string(nil), values...)
+			}
+			return next.RoundTrip(req)
+		})
+	}
+}
+
+type requestIdKey struct{}
+
+//ContextWithRequestID returns a copy of ctx carrying the request id to send
+//by RequestIDMiddleware with the requests made with it.
+func ContextWithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIdKey{}, id)
+}
+
+//RequestIDFromContext returns the request id carried by ctx, if any.
+func RequestIDFromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(requestIdKey{}).(string)
+	return id, ok && id != ""
+}
+
+//RequestIDMiddleware sends a request id in the X-Request-Id header of every request
+//which does not have one yet. The id is the one carried by the request context
+//(see ContextWithRequestID) or, when there is none, a random uuid.
+func RequestIDMiddleware() Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			if req.Header.Get(RequestIdHeader) != "" {
+				return next.RoundTrip(req)
+			}
+			id, ok := RequestIDFromContext(req.Context())
+			if !ok {
+				id = uuid.NewString()
+			}
+			req = req.Clone(req.Context())
+			req.Header.Set(RequestIdHeader, id)
+			return next.RoundTrip(req)
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	is2 "github.com/matryer/is"
+)
+
+//tracing records the order in which the middlewares see requests and responses.
+func tracing(name string, trace *[]string) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			*trace = append(*trace, name+" request")
+			resp, err := next.RoundTrip(req)
+			if err != nil {
+				*trace = append(*trace, name+" error")
+			} else {
+				*trace = append(*trace, name+" response "+resp.Status[:3])
+			}
+			return resp, err
+		})
+	}
+}
+
+func TestMiddlewareOrder(t *testing.T) {
+	is := is2.New(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	var trace []string
+	gate := NewGateway(WithApiUrl(srv.URL),
+		WithMiddleware(tracing("outer", &trace), tracing("middle", &trace)),
+		WithMiddleware(tracing("inner", &trace)))
+	is.NoErr(gate.Delete(context.Background(), uuid.New(), "0"))
+
+	is.Equal(trace, []string{
+		"outer request", "middle request", "inner request",
+		"inner response 204", "middle response 204", "outer response 204",
+	})
+}
+
+func TestMiddlewareSeesEveryAttemptAndErrors(t *testing.T) {
+	is := is2.New(t)
+	srv, _ := newFlakyServer(1, http.StatusServiceUnavailable, http.StatusNoContent, "")
+	defer srv.Close()
+
+	var trace []string
+	gate := NewGateway(WithApiUrl(srv.URL), WithRetryPolicy(testRetryPolicy), WithMiddleware(tracing("mw", &trace)))
+	is.NoErr(gate.Delete(context.Background(), uuid.New(), "0"))
+	is.Equal(trace, []string{"mw request", "mw response 503", "mw request", "mw response 204"})
+
+	trace = nil
+	gate = NewGateway(WithApiUrl("http://127.0.0.1:1"), WithMiddleware(tracing("mw", &trace)))
+	is.True(gate.Delete(context.Background(), uuid.New(), "0") != nil)
+	is.Equal(trace, []string{"mw request", "mw error"})
+}
+
+func TestMiddlewareCanShortCircuit(t *testing.T) {
+	is := is2.New(t)
+	refused := errors.New("refused by middleware")
+	deny := func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			return nil, refused
+		})
+	}
+	gate := NewGateway(WithApiUrl("http://127.0.0.1:1"), WithMiddleware(deny))
+	_, err := gate.Get(context.Background(), uuid.New())
+	is.True(errors.Is(err, refused))
+}
+
+func TestHeaderAndRequestIDMiddlewares(t *testing.T) {
+	is := is2.New(t)
+	var headers []http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers = append(headers, r.Header.Clone())
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	hc := srv.Client()
+	gate := NewGateway(WithApiUrl(srv.URL), WithHttpClient(hc), WithUserAgent("form3-test"), WithMiddleware(
+		HeaderMiddleware(http.Header{"Authorization": {"Bearer abc"}, "user-agent": {"custom"}}),
+		RequestIDMiddleware(),
+	))
+	is.NoErr(gate.Delete(context.Background(), uuid.New(), "0"))
+	is.NoErr(gate.Delete(ContextWithRequestID(context.Background(), "req-42"), uuid.New(), "0"))
+
+	is.Equal(headers[0].Get("Authorization"), "Bearer abc")
+	is.Equal(headers[0].Get("User-Agent"), "custom")
+	_, err := uuid.Parse(headers[0].Get(RequestIdHeader))
+	is.NoErr(err)
+	is.Equal(headers[1].Get(RequestIdHeader), "req-42")
+	is.True(hc.Transport == srv.Client().Transport) //the given client is not modified
+}
+
+func TestRequestIDMiddlewareKeepsExistingHeader(t *testing.T) {
+	is := is2.New(t)
+	var got string
+	rt := Chain(RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		got = req.Header.Get(RequestIdHeader)
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+	}), RequestIDMiddleware())
+
+	req, _ := http.NewRequest(http.MethodGet, "http://localhost", strings.NewReader(""))
+	req.Header.Set(RequestIdHeader, "mine")
+	_, err := rt.RoundTrip(req)
+	is.NoErr(err)
+	is.Equal(got, "mine")
+}
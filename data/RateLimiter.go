@@ -0,0 +1,114 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+//ErrRateLimited is reported when a request is not sent because the rate limit of the
+//client is exceeded: right away when failing fast, or when the wait for the rate limit
+//would go past the deadline of the context.
+var ErrRateLimited = errors.New("rate limit of the client exceeded")
+
+//RateLimit describes how many requests can be sent to the account api.
+//The zero value disables the limit.
+type RateLimit struct {
+
+	//RequestsPerSecond is the sustained rate of requests allowed.
+	RequestsPerSecond float64
+
+	//Burst is the number of requests that can be sent at once after a quiet period, 1 when zero.
+	Burst int
+
+	//FailFast makes requests exceeding the limit fail with ErrRateLimited instead of waiting.
+	FailFast bool
+}
+
+//RateLimiter is a token bucket: it holds up to Burst tokens, refilled at RequestsPerSecond,
+//and each request takes one. It is safe for concurrent use.
+type RateLimiter struct {
+	limit  RateLimit
+	now    func() time.Time
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+//NewRateLimiter creates a limiter, its bucket full.
+func NewRateLimiter(l RateLimit) *RateLimiter {
+	if l.Burst <= 0 {
+		l.Burst = 1
+	}
+	return &RateLimiter{limit: l, now: time.Now, tokens: float64(l.Burst)}
+}
+
+//reserve takes a token and returns how long to wait before using it. When the wait exceeds
+//max (if positive) the token is not taken and ok is false.
+func (l *RateLimiter) reserve(max time.Duration, limited bool) (wait time.Duration, ok bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	if !l.last.IsZero() {
+		l.tokens += now.Sub(l.last).Seconds() * l.limit.RequestsPerSecond
+		if l.tokens > float64(l.limit.Burst) {
+			l.tokens = float64(l.limit.Burst)
+		}
+	}
+	l.last = now
+
+	if l.tokens >= 1 {
+		l.tokens--
+		return 0, true
+	}
+	wait = time.Duration((1 - l.tokens) / l.limit.RequestsPerSecond * float64(time.Second))
+	if limited && wait > max {
+		return wait, false
+	}
+	//the token is owed: later requests wait for it to be refilled too
+	l.tokens--
+	return wait, true
+}
+
+//cancel gives back a reserved token which was not used.
+func (l *RateLimiter) cancel() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.tokens++
+	if l.tokens > float64(l.limit.Burst) {
+		l.tokens = float64(l.limit.Burst)
+	}
+}
+
+//Allow takes a token if one is available right now.
+func (l *RateLimiter) Allow() bool {
+	_, ok := l.reserve(0, true)
+	return ok
+}
+
+//Wait takes a token, waiting for it to be available unless the limit fails fast.
+//Fails with ErrRateLimited when failing fast or when the token would only be available
+//after the deadline of ctx, and with the error of ctx if it is done while waiting.
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	if l.limit.FailFast {
+		if !l.Allow() {
+			return ErrRateLimited
+		}
+		return nil
+	}
+	max, limited := time.Duration(0), false
+	if deadline, ok := ctx.Deadline(); ok {
+		max, limited = deadline.Sub(l.now()), true
+	}
+	wait, ok := l.reserve(max, limited)
+	if !ok {
+		return fmt.Errorf("%w: waiting %s would exceed the context deadline", ErrRateLimited, wait.Round(time.Millisecond))
+	}
+	if err := sleep(ctx, wait); err != nil {
+		l.cancel()
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	is2 "github.com/matryer/is"
+)
+
+func TestRateLimiterRefillsTokens(t *testing.T) {
+	is := is2.New(t)
+	now := time.Date(2021, 3, 25, 10, 0, 0, 0, time.UTC)
+	l := NewRateLimiter(RateLimit{RequestsPerSecond: 2, Burst: 3})
+	l.now = func() time.Time { return now }
+
+	//a full bucket allows a burst
+	for i := 0; i < 3; i++ {
+		is.True(l.Allow())
+	}
+	is.True(!l.Allow())
+
+	now = now.Add(500 * time.Millisecond)
+	is.True(l.Allow())
+	is.True(!l.Allow())
+
+	//the bucket does not hold more than the burst
+	now = now.Add(time.Hour)
+	for i := 0; i < 3; i++ {
+		is.True(l.Allow())
+	}
+	is.True(!l.Allow())
+
+	//waiting requests queue up for the following tokens
+	wait, ok := l.reserve(0, false)
+	is.True(ok)
+	is.Equal(wait, 500*time.Millisecond)
+	wait, _ = l.reserve(0, false)
+	is.Equal(wait, time.Second)
+}
+
+func TestRateLimiterWait(t *testing.T) {
+	is := is2.New(t)
+	l := NewRateLimiter(RateLimit{RequestsPerSecond: 50})
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		is.NoErr(l.Wait(context.Background()))
+	}
+	is.True(time.Since(start) >= 40*time.Millisecond)
+}
+
+func TestRateLimiterFailFast(t *testing.T) {
+	is := is2.New(t)
+	l := NewRateLimiter(RateLimit{RequestsPerSecond: 1, FailFast: true})
+	is.NoErr(l.Wait(context.Background()))
+	is.True(errors.Is(l.Wait(context.Background()), ErrRateLimited))
+}
+
+func TestRateLimiterHonorsDeadline(t *testing.T) {
+	is := is2.New(t)
+	l := NewRateLimiter(RateLimit{RequestsPerSecond: 1})
+	is.NoErr(l.Wait(context.Background()))
+
+	//the next token comes in a second, after the deadline: fail right away
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	is.True(errors.Is(l.Wait(ctx), ErrRateLimited))
+	is.True(time.Since(start) < 50*time.Millisecond)
+
+	//cancelled while waiting: the token is given back
+	ctx, cancel = context.WithCancel(context.Background())
+	time.AfterFunc(10*time.Millisecond, cancel)
+	is.True(errors.Is(l.Wait(ctx), context.Canceled))
+	is.True(l.tokens > -1)
+}
+
+func TestGatewayRateLimitIsShared(t *testing.T) {
+	is := is2.New(t)
+	var requests int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	gate := NewGateway(WithApiUrl(srv.URL), WithRateLimit(RateLimit{RequestsPerSecond: 1, Burst: 2, FailFast: true}))
+	var wg sync.WaitGroup
+	var limited int32
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := gate.Delete(context.Background(), uuid.New(), "0"); errors.Is(err, ErrRateLimited) {
+				atomic.AddInt32(&limited, 1)
+			}
+		}()
+	}
+	wg.Wait()
+	is.Equal(atomic.LoadInt32(&requests), int32(2))
+	is.Equal(atomic.LoadInt32(&limited), int32(3))
+}
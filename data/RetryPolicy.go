@@ -0,0 +1,138 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+//RetryPolicy describes how requests failing with a transient error are retried.
+//The zero value disables retries.
+type RetryPolicy struct {
+
+	//MaxAttempts is the maximum number of times a request is sent, the first one included.
+	MaxAttempts int
+
+	//BaseDelay is the wait before the first retry. It doubles on every following retry.
+	BaseDelay time.Duration
+
+	//MaxDelay caps the wait between two attempts. No cap is applied when zero.
+	//A request asked to wait longer by a Retry-After header is not retried.
+	MaxDelay time.Duration
+
+	//Jitter is the fraction (between 0 and 1) of each wait that is randomized
+	//so that clients failing at the same time do not retry at the same time.
+	Jitter float64
+
+	//RetryableStatus lists the http status codes worth retrying.
+	RetryableStatus []int
+}
+
+//DefaultRetryPolicy retries up to two times the requests failing due to the network
+//or with a status that usually indicates a transient condition.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	BaseDelay:   100 * time.Millisecond,
+	MaxDelay:    2 * time.Second,
+	Jitter:      0.5,
+	RetryableStatus: []int{
+		http.StatusTooManyRequests,
+		http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout,
+	},
+}
+
+//shouldRetry reports whether another attempt should follow the given one.
+//Network errors are retried unless ctx is done, responses only when their status is retryable.
+//Requests which could not be authenticated are not retried, the next attempt would fail alike.
+//PATCH requests are never retried: when the first attempt was applied but its response lost,
+//the retry fails with a version conflict although the change went through.
+func (p RetryPolicy) shouldRetry(ctx context.Context, method string, attempt int, resp *http.Response, err error) bool {
+	if attempt >= p.MaxAttempts || method == http.MethodPatch || ctx.Err() != nil {
+		return false
+	}
+	if err != nil {
+		return !errors.Is(err, ErrAuthentication)
+	}
+	for _, code := range p.RetryableStatus {
+		if resp.StatusCode == code {
+			return true
+		}
+	}
+	return false
+}
+
+//delay computes the wait before the attempt following the given one.
+//A Retry-After header sent by the api takes precedence over the backoff. When it asks
+//for a wait longer than MaxDelay or than what is left before the deadline of ctx, it
+//reports false: the request should not be retried and the response returned instead.
+func (p RetryPolicy) delay(ctx context.Context, attempt int, resp *http.Response) (time.Duration, bool) {
+	if resp != nil {
+		if wait, ok := retryAfter(resp.Header.Get("Retry-After")); ok {
+			if p.MaxDelay > 0 && wait > p.MaxDelay {
+				return wait, false
+			}
+			if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < wait {
+				return wait, false
+			}
+			return wait, true
+		}
+	}
+
+	wait := p.BaseDelay
+	for i := 1; i < attempt; i++ {
+		wait *= 2
+		if p.MaxDelay > 0 && wait > p.MaxDelay {
+			break
+		}
+	}
+	if p.MaxDelay > 0 && wait > p.MaxDelay {
+		wait = p.MaxDelay
+	}
+	if p.Jitter > 0 {
+		jitter := p.Jitter
+		if jitter > 1 {
+			jitter = 1
+		}
+		wait -= time.Duration(rand.Float64() * jitter * float64(wait))
+	}
+	return wait, true
+}
+
+//retryAfter parses the value of a Retry-After header, either in seconds or as an http date.
+func retryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(value); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second, true
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		wait := time.Until(at)
+		if wait < 0 {
+			wait = 0
+		}
+		return wait, true
+	}
+	return 0, false
+}
+
+//sleep waits for the given duration or until ctx is done, whichever happens first.
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,360 @@
+package data
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	is2 "github.com/matryer/is"
+)
+
+var testRetryPolicy = RetryPolicy{
+	MaxAttempts:     4,
+	BaseDelay:       time.Millisecond,
+	MaxDelay:        5 * time.Millisecond,
+	Jitter:          0.5,
+	RetryableStatus: DefaultRetryPolicy.RetryableStatus,
+}
+
+func TestGetRetriesTransientFailures(t *testing.T) {
+	is := is2.New(t)
+	dto, _ := newAccount([]string{"Kim"})
+	srv, attempts := newFlakyServer(3, http.StatusServiceUnavailable, http.StatusOK, `{"data":{"id":"`+dto.Data.ID+`"}}`)
+	defer srv.Close()
+
+	gate := NewGateway(WithApiUrl(srv.URL), WithRetryPolicy(testRetryPolicy))
+	found, err := gate.Get(context.Background(), uuid.MustParse(dto.Data.ID))
+
+	is.NoErr(err)
+	is.Equal(found.Data.ID, dto.Data.ID)
+	is.Equal(atomic.LoadInt32(attempts), int32(4))
+}
+
+func TestGetGivesUpAfterMaxAttempts(t *testing.T) {
+	is := is2.New(t)
+	srv, attempts := newFlakyServer(10, http.StatusBadGateway, http.StatusOK, `{}`)
+	defer srv.Close()
+
+	gate := NewGateway(WithApiUrl(srv.URL), WithRetryPolicy(testRetryPolicy))
+	_, err := gate.Get(context.Background(), uuid.New())
+
+	var apiErr *APIError
+	is.True(errors.As(err, &apiErr))
+	is.Equal(apiErr.StatusCode, http.StatusBadGateway)
+	is.Equal(atomic.LoadInt32(attempts), int32(testRetryPolicy.MaxAttempts))
+}
+
+func TestNoRetryOnPermanentFailure(t *testing.T) {
+	is := is2.New(t)
+	srv, attempts := newFlakyServer(10, http.StatusNotFound, http.StatusOK, `{}`)
+	defer srv.Close()
+
+	gate := NewGateway(WithApiUrl(srv.URL), WithRetryPolicy(testRetryPolicy))
+	err := gate.Delete(context.Background(), uuid.New(), "0")
+
+	is.True(errors.Is(err, ErrNotFound))
+	is.Equal(atomic.LoadInt32(attempts), int32(1))
+}
+
+func TestNoRetryByDefault(t *testing.T) {
+	is := is2.New(t)
+	srv, attempts := newFlakyServer(1, http.StatusServiceUnavailable, http.StatusNoContent, ``)
+	defer srv.Close()
+
+	err := NewGateway(WithApiUrl(srv.URL)).Delete(context.Background(), uuid.New(), "0")
+
+	is.True(err != nil)
+	is.Equal(atomic.LoadInt32(attempts), int32(1))
+}
+
+func TestCreateRetriesWithSameBody(t *testing.T) {
+	is := is2.New(t)
+	dto, _ := newAccount([]string{"Kim", "Emma"})
+	var bodies []string
+	var attempts int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		if atomic.AddInt32(&attempts, 1) < 3 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write(body)
+	}))
+	defer srv.Close()
+
+	gate := NewGateway(WithApiUrl(srv.URL), WithRetryPolicy(testRetryPolicy))
+	created, err := gate.Create(context.Background(), dto)
+
+	is.NoErr(err)
+	is.Equal(created.Data.ID, dto.Data.ID)
+	is.Equal(len(bodies), 3)
+	is.Equal(bodies[0], bodies[2])
+}
+
+func TestCreateRetryReturnsAccountStoredByEarlierAttempt(t *testing.T) {
+	is := is2.New(t)
+	dto, _ := newAccount([]string{"Kim"})
+	var stored []byte
+	var posts int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && stored != nil:
+			_, _ = w.Write(stored)
+		case r.Method == http.MethodGet:
+			w.WriteHeader(http.StatusNotFound)
+		case atomic.AddInt32(&posts, 1) == 1:
+			//the account is stored but the answer is lost on the way
+			stored, _ = ioutil.ReadAll(r.Body)
+			w.WriteHeader(http.StatusGatewayTimeout)
+		default:
+			w.WriteHeader(http.StatusConflict)
+			_, _ = w.Write([]byte(`{"error_message":"Account cannot be created as it violates a duplicate constraint"}`))
+		}
+	}))
+	defer srv.Close()
+
+	gate := NewGateway(WithApiUrl(srv.URL), WithRetryPolicy(testRetryPolicy))
+	created, err := gate.Create(context.Background(), dto)
+
+	is.NoErr(err)
+	is.Equal(created.Data.ID, dto.Data.ID)
+	is.Equal(atomic.LoadInt32(&posts), int32(2))
+}
+
+func TestCreateRetryConflictWithOtherAccountIsDuplicate(t *testing.T) {
+	is := is2.New(t)
+	dto, _ := newAccount([]string{"Kim"})
+	existing, _ := newAccount([]string{"Emma"})
+	existing.Data.ID = dto.Data.ID
+	stored, _ := json.Marshal(existing)
+	var posts int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet:
+			_, _ = w.Write(stored)
+		case atomic.AddInt32(&posts, 1) == 1:
+			w.WriteHeader(http.StatusBadGateway)
+		default:
+			w.WriteHeader(http.StatusConflict)
+		}
+	}))
+	defer srv.Close()
+
+	gate := NewGateway(WithApiUrl(srv.URL), WithRetryPolicy(testRetryPolicy))
+	_, err := gate.Create(context.Background(), dto)
+
+	is.True(errors.Is(err, ErrDuplicate)) //the account found is not the one sent
+	is.Equal(atomic.LoadInt32(&posts), int32(2))
+}
+
+func TestCreateConflictOnFirstAttemptIsDuplicate(t *testing.T) {
+	is := is2.New(t)
+	var gets int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			atomic.AddInt32(&gets, 1)
+		}
+		w.WriteHeader(http.StatusConflict)
+	}))
+	defer srv.Close()
+
+	dto, _ := newAccount([]string{"Kim"})
+	gate := NewGateway(WithApiUrl(srv.URL), WithRetryPolicy(testRetryPolicy))
+	_, err := gate.Create(context.Background(), dto)
+
+	is.True(errors.Is(err, ErrDuplicate))
+	is.Equal(atomic.LoadInt32(&gets), int32(0)) //the account was taken before this call
+}
+
+func TestUpdateIsNotRetried(t *testing.T) {
+	is := is2.New(t)
+	srv, attempts := newFlakyServer(1, http.StatusBadGateway, http.StatusOK, `{}`)
+	defer srv.Close()
+
+	gate := NewGateway(WithApiUrl(srv.URL), WithRetryPolicy(testRetryPolicy))
+	_, err := gate.Update(context.Background(), uuid.New(), AccountPatchDto{})
+
+	var apiErr *APIError
+	is.True(errors.As(err, &apiErr)) //the outcome is unknown, a retry could report a conflict for an applied change
+	is.Equal(apiErr.StatusCode, http.StatusBadGateway)
+	is.Equal(atomic.LoadInt32(attempts), int32(1))
+}
+
+func TestDeleteRetryNotFindingTheAccountSucceeds(t *testing.T) {
+	is := is2.New(t)
+	//the first attempt deletes the account but its answer is lost on the way
+	srv, attempts := newFlakyServer(1, http.StatusGatewayTimeout, http.StatusNotFound, ``)
+	defer srv.Close()
+
+	gate := NewGateway(WithApiUrl(srv.URL), WithRetryPolicy(testRetryPolicy))
+	err := gate.Delete(context.Background(), uuid.New(), "0")
+
+	is.NoErr(err)
+	is.Equal(atomic.LoadInt32(attempts), int32(2))
+}
+
+func TestRetryOnNetworkError(t *testing.T) {
+	is := is2.New(t)
+	var attempts int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			//drop the connection without answering
+			conn, _, _ := w.(http.Hijacker).Hijack()
+			conn.Close()
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	gate := NewGateway(WithApiUrl(srv.URL), WithRetryPolicy(testRetryPolicy))
+	err := gate.Delete(context.Background(), uuid.New(), "0")
+
+	is.NoErr(err)
+	is.Equal(atomic.LoadInt32(&attempts), int32(2))
+}
+
+func TestRetryHonorsRetryAfter(t *testing.T) {
+	is := is2.New(t)
+	var attempts int32
+	var first, second time.Time
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			first = time.Now()
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		second = time.Now()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	policy := testRetryPolicy
+	policy.MaxDelay = 2 * time.Second
+	gate := NewGateway(WithApiUrl(srv.URL), WithRetryPolicy(policy))
+	err := gate.Delete(context.Background(), uuid.New(), "0")
+
+	is.NoErr(err)
+	is.True(second.Sub(first) >= time.Second) //waited as told instead of the few milliseconds of backoff
+}
+
+func TestRetryAfterBeyondLimitsIsNotRetried(t *testing.T) {
+	var attempts int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		w.Header().Set("Retry-After", "3600")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		name     string
+		maxDelay time.Duration
+		timeout  time.Duration
+	}{
+		{"longer than the max delay", 5 * time.Millisecond, 0},
+		{"longer than the deadline", 0, 5 * time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			is := is2.New(t)
+			atomic.StoreInt32(&attempts, 0)
+			ctx := context.Background()
+			if tt.timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, tt.timeout)
+				defer cancel()
+			}
+			policy := testRetryPolicy
+			policy.MaxDelay = tt.maxDelay
+
+			start := time.Now()
+			_, err := NewGateway(WithApiUrl(srv.URL), WithRetryPolicy(policy)).Get(ctx, uuid.New())
+
+			var apiErr *APIError
+			is.True(errors.As(err, &apiErr)) //the response is returned instead of a context error
+			is.Equal(apiErr.StatusCode, http.StatusServiceUnavailable)
+			is.Equal(atomic.LoadInt32(&attempts), int32(1))
+			is.True(time.Since(start) < time.Second)
+		})
+	}
+}
+
+func TestRetryStopsWhenContextIsDone(t *testing.T) {
+	is := is2.New(t)
+	srv, attempts := newFlakyServer(10, http.StatusServiceUnavailable, http.StatusOK, `{}`)
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	policy := testRetryPolicy
+	policy.MaxAttempts = 100
+	policy.BaseDelay, policy.MaxDelay = time.Second, time.Second
+
+	gate := NewGateway(WithApiUrl(srv.URL), WithRetryPolicy(policy))
+	_, err := gate.Get(ctx, uuid.New())
+
+	is.True(errors.Is(err, context.DeadlineExceeded))
+	is.Equal(atomic.LoadInt32(attempts), int32(1))
+}
+
+func TestRetryDelay(t *testing.T) {
+	is := is2.New(t)
+	ctx := context.Background()
+	policy := RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+	delay := func(attempt int, resp *http.Response) time.Duration {
+		wait, ok := policy.delay(ctx, attempt, resp)
+		is.True(ok)
+		return wait
+	}
+
+	is.Equal(delay(1, nil), 100*time.Millisecond)
+	is.Equal(delay(2, nil), 200*time.Millisecond)
+	is.Equal(delay(3, nil), 400*time.Millisecond)
+	is.Equal(delay(10, nil), time.Second)
+
+	policy.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		d := delay(2, nil)
+		is.True(d > 100*time.Millisecond && d <= 200*time.Millisecond)
+	}
+
+	policy.MaxDelay = 5 * time.Second
+	resp := &http.Response{Header: http.Header{"Retry-After": []string{"3"}}}
+	is.Equal(delay(1, resp), 3*time.Second)
+
+	policy.MaxDelay = time.Second
+	_, ok := policy.delay(ctx, 1, resp)
+	is.True(!ok) //longer than MaxDelay
+
+	policy.MaxDelay = 0
+	short, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+	_, ok = policy.delay(short, 1, resp)
+	is.True(!ok) //longer than the time left before the deadline
+}
+
+//newFlakyServer answers failures times with the failing status and
+//then with the given status and body. It counts the requests received.
+func newFlakyServer(failures int32, failing, status int, body string) (*httptest.Server, *int32) {
+	var attempts int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&attempts, 1) <= failures {
+			w.WriteHeader(failing)
+			return
+		}
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(body))
+	}))
+	return srv, &attempts
+}
@@ -0,0 +1,81 @@
+package form3_task
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/petegabriel/form3_task/data"
+)
+
+var (
+	//ErrInvalidID is returned when the given account id is not a valid uuid.
+	ErrInvalidID = errors.New("given id must be a valid uuid type")
+
+	//ErrEmptyPatch is returned when updating an account with a nil patch or one changing nothing.
+	ErrEmptyPatch = errors.New("given patch must change at least one attribute")
+
+	//ErrNotFound is returned when the account does not exist.
+	ErrNotFound = data.ErrNotFound
+
+	//ErrVersionConflict is returned when the given version does not match the account's current version.
+	ErrVersionConflict = data.ErrVersionConflict
+
+	//ErrDuplicate is returned when creating an account which id is already in use.
+	ErrDuplicate = data.ErrDuplicate
+
+	//ErrValidation is returned when the account api rejects the account data.
+	ErrValidation = data.ErrValidation
+
+	//ErrUnauthorized is returned when the account api rejects the credentials of a request.
+	ErrUnauthorized = data.ErrUnauthorized
+
+	//ErrAuthentication is returned when the Authenticator fails to authenticate a request.
+	ErrAuthentication = data.ErrAuthentication
+
+	//ErrRateLimited is returned when a request is not sent because the rate limit of the client is exceeded.
+	ErrRateLimited = data.ErrRateLimited
+
+	//ErrInvalidCountry is returned when a code is not an ISO 3166-1 alpha-2 country code.
+	ErrInvalidCountry = errors.New("not an ISO 3166-1 alpha-2 country code")
+
+	//ErrInvalidCurrency is returned when a code is not an ISO 4217 currency code.
+	ErrInvalidCurrency = errors.New("not an ISO 4217 currency code")
+
+	//ErrConflictingAccount is returned by CreateOrGetAccount when the account already exists with other attributes.
+	ErrConflictingAccount = errors.New("account already exists with different attributes")
+
+	//ErrCountryNotSupported is returned when building an account in a country without Form3 rules.
+	ErrCountryNotSupported = errors.New("country not supported by Form3")
+)
+
+//APIError carries the details of an error response from the account api.
+//It can be retrieved from any returned error with errors.As.
+type APIError = data.APIError
+
+//FieldError describes why a field of an account is not valid. It is found both in
+//ValidationError, when the account is checked before being sent, and in APIError,
+//when the account api rejects it.
+type FieldError = data.FieldError
+
+//DecodeError is returned when the account api sends an account holding
+//a value which cannot be read, such as a malformed id.
+type DecodeError struct {
+
+	//Field is the name of the attribute as sent by the account api (e.g. 'organisation_id').
+	Field string
+
+	//Value is the content of the field as received.
+	Value string
+
+	//Err is the reason why the value could not be read.
+	Err error
+}
+
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("cannot decode account %s %q: %s", e.Field, e.Value, e.Err)
+}
+
+//Unwrap returns the reason why the value could not be read.
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,194 @@
+package fake
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	is2 "github.com/matryer/is"
+	"github.com/petegabriel/form3_task/data"
+)
+
+//gateways returns both fakes: the in-memory gateway and the real gateway talking to the fake server.
+func gateways(t *testing.T) map[string]data.AccountApiGateway {
+	srv := NewServer()
+	t.Cleanup(srv.Close)
+	return map[string]data.AccountApiGateway{
+		"in-memory": NewGateway(),
+		"http":      data.NewGateway(data.WithApiUrl(srv.AccountsURL())),
+	}
+}
+
+func TestAccountLifecycle(t *testing.T) {
+	for name, gate := range gateways(t) {
+		t.Run(name, func(t *testing.T) {
+			is := is2.New(t)
+			ctx := context.Background()
+			id := uuid.New()
+			dto := data.NewAccountDto(id, uuid.New(), "GB", []string{"Jane", "Doe"})
+
+			created, err := gate.Create(ctx, dto)
+			is.NoErr(err)
+			is.Equal(created.Data.Version, 0)
+			is.True(created.Data.CreatedOn != "")
+
+			_, err = gate.Create(ctx, dto)
+			is.True(errors.Is(err, data.ErrDuplicate))
+
+			found, err := gate.Get(ctx, id)
+			is.NoErr(err)
+			is.Equal(found, created)
+
+			bic := "NWBKGB22"
+			patch := data.AccountPatchDto{Data: data.PatchData{ID: id.String(), Version: 0, Attributes: data.AttributesPatch{Bic: &bic}}}
+			updated, err := gate.Update(ctx, id, patch)
+			is.NoErr(err)
+			is.Equal(updated.Data.Version, 1)
+			is.Equal(updated.Data.Attributes.Bic, bic)
+			is.Equal(updated.Data.Attributes.Name, []string{"Jane", "Doe"})
+
+			_, err = gate.Update(ctx, id, patch)
+			is.True(errors.Is(err, data.ErrVersionConflict))
+
+			err = gate.Delete(ctx, id, "0")
+			is.True(errors.Is(err, data.ErrVersionConflict))
+			is.NoErr(gate.Delete(ctx, id, "1"))
+
+			_, err = gate.Get(ctx, id)
+			is.True(errors.Is(err, data.ErrNotFound))
+			err = gate.Delete(ctx, id, "1")
+			is.True(errors.Is(err, data.ErrNotFound))
+		})
+	}
+}
+
+func TestUpdateDetailedAttributes(t *testing.T) {
+	for name, gate := range gateways(t) {
+		t.Run(name, func(t *testing.T) {
+			is := is2.New(t)
+			ctx := context.Background()
+			id := uuid.New()
+			_, err := gate.Create(ctx, data.NewAccountDto(id, uuid.New(), "GB", []string{"Jane"}))
+			is.NoErr(err)
+
+			status, title := "closed", "Dr"
+			ident := &data.PrivateIdentification{BirthDate: "2017-07-23", Country: "GB"}
+			patch := data.AccountPatchDto{Data: data.PatchData{ID: id.String(), Attributes: data.AttributesPatch{
+				Status: &status, Title: &title, PrivateIdentification: ident,
+			}}}
+			updated, err := gate.Update(ctx, id, patch)
+			is.NoErr(err)
+			is.Equal(updated.Data.Attributes.Status, status)
+			is.Equal(updated.Data.Attributes.Title, title)
+			is.Equal(updated.Data.Attributes.PrivateIdentification, ident)
+			is.Equal(updated.Data.Attributes.Name, []string{"Jane"})
+		})
+	}
+}
+
+func TestAccountsAreCopied(t *testing.T) {
+	is := is2.New(t)
+	ctx := context.Background()
+	gate := NewGateway()
+	id := uuid.New()
+	dto := data.NewAccountDto(id, uuid.New(), "GB", []string{"Jane", "Doe"})
+	dto.Data.Attributes.PrivateIdentification = &data.PrivateIdentification{Country: "GB"}
+
+	created, err := gate.Create(ctx, dto)
+	is.NoErr(err)
+	dto.Data.Attributes.Name[0] = "Sent"
+	created.Data.Attributes.Name[0] = "Created"
+	created.Data.Attributes.PrivateIdentification.Country = "PT"
+
+	found, err := gate.Get(ctx, id)
+	is.NoErr(err)
+	found.Data.Attributes.Name[1] = "Found"
+	page, err := gate.List(ctx, data.ListParams{})
+	is.NoErr(err)
+	page.Data[0].Attributes.AlternativeNames = append(page.Data[0].Attributes.AlternativeNames, "Listed")
+	page.Data[0].Attributes.Name[0] = "Listed"
+
+	found, err = gate.Get(ctx, id)
+	is.NoErr(err)
+	is.Equal(found.Data.Attributes.Name, []string{"Jane", "Doe"})
+	is.Equal(found.Data.Attributes.PrivateIdentification.Country, "GB")
+	is.Equal(len(found.Data.Attributes.AlternativeNames), 0)
+}
+
+func TestCreateInvalidAccount(t *testing.T) {
+	for name, gate := range gateways(t) {
+		t.Run(name, func(t *testing.T) {
+			is := is2.New(t)
+			dto := data.NewAccountDto(uuid.New(), uuid.New(), "gb", []string{"Jane", ""})
+
+			_, err := gate.Create(context.Background(), dto)
+
+			is.True(errors.Is(err, data.ErrValidation))
+			var apiErr *data.APIError
+			is.True(errors.As(err, &apiErr))
+			is.Equal(apiErr.StatusCode, 400)
+			is.True(strings.HasPrefix(apiErr.Message, "validation failure list:\n"))
+			is.True(strings.Contains(apiErr.Message, "country in body should match '^[A-Z]{2}$'"))
+			is.True(strings.Contains(apiErr.Message, "name.1 in body should be at least 1 chars long"))
+			is.Equal(len(apiErr.Violations), 2)
+		})
+	}
+}
+
+func TestInvalidAccountViolationsMatch(t *testing.T) {
+	is := is2.New(t)
+	dto := data.NewAccountDto(uuid.New(), uuid.New(), "gb", []string{"Jane", ""})
+
+	violations := map[string][]data.FieldError{}
+	for name, gate := range gateways(t) {
+		_, err := gate.Create(context.Background(), dto)
+		var apiErr *data.APIError
+		is.True(errors.As(err, &apiErr))
+		violations[name] = apiErr.Violations
+	}
+
+	is.Equal(violations["in-memory"], violations["http"])
+	is.Equal(violations["in-memory"], []data.FieldError{
+		{Field: "country", Rule: "format", Message: "should match '^[A-Z]{2}$'"},
+		{Field: "name.1", Rule: "min_length", Message: "should be at least 1 chars long"},
+	})
+}
+
+func TestList(t *testing.T) {
+	for name, gate := range gateways(t) {
+		t.Run(name, func(t *testing.T) {
+			is := is2.New(t)
+			ctx := context.Background()
+			var ids []string
+			for _, country := range []string{"GB", "PT", "GB", "GB", "DE"} {
+				dto := data.NewAccountDto(uuid.New(), uuid.New(), country, []string{"Jane"})
+				_, err := gate.Create(ctx, dto)
+				is.NoErr(err)
+				if country == "GB" {
+					ids = append(ids, dto.Data.ID)
+				}
+			}
+
+			params := data.ListParams{PageSize: 2, Filter: map[string]string{"country": "GB"}}
+			first, err := gate.List(ctx, params)
+			is.NoErr(err)
+			is.Equal(len(first.Data), 2)
+			is.Equal(first.Data[0].ID, ids[0])
+			is.True(first.Links.Next != "")
+			is.Equal(first.Links.Prev, "")
+
+			params.PageNumber = 1
+			second, err := gate.List(ctx, params)
+			is.NoErr(err)
+			is.Equal(len(second.Data), 1)
+			is.Equal(second.Data[0].ID, ids[2])
+			is.Equal(second.Links.Next, "")
+			is.True(second.Links.Prev != "")
+
+			_, err = gate.List(ctx, data.ListParams{Filter: map[string]string{"colour": "blue"}})
+			is.True(errors.Is(err, data.ErrValidation))
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package fake
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/petegabriel/form3_task/data"
+)
+
+//Gateway is an in-memory implementation of data.AccountApiGateway.
+//It follows the rules of the account api, returning the same errors the real
+//gateway would, so the code using it can be tested without a running api.
+//A Gateway is safe for concurrent use by multiple goroutines.
+type Gateway struct {
+	store *store
+}
+
+//NewGateway creates a new instance of Gateway without any account.
+func NewGateway() *Gateway {
+	return &Gateway{store: newStore()}
+}
+
+//Create a new account
+func (g *Gateway) Create(ctx context.Context, dto data.AccountDto) (data.AccountDto, error) {
+	if err := ctx.Err(); err != nil {
+		return data.AccountDto{}, err
+	}
+	created, f := g.store.create(dto)
+	if f != nil {
+		return data.AccountDto{}, f.apiError(data.ErrDuplicate)
+	}
+	return created, nil
+}
+
+//Delete an account by id and version
+func (g *Gateway) Delete(ctx context.Context, uid uuid.UUID, vrs string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	version, err := strconv.Atoi(vrs)
+	if err != nil {
+		return (&failure{http.StatusBadRequest, "invalid version number"}).apiError(nil)
+	}
+	if f := g.store.delete(uid.String(), version); f != nil {
+		return f.apiError(data.ErrVersionConflict)
+	}
+	return nil
+}
+
+//Get an account by id
+func (g *Gateway) Get(ctx context.Context, uid uuid.UUID) (data.AccountDto, error) {
+	if err := ctx.Err(); err != nil {
+		return data.AccountDto{}, err
+	}
+	found, f := g.store.get(uid.String())
+	if f != nil {
+		return data.AccountDto{}, f.apiError(nil)
+	}
+	return found, nil
+}
+
+//Update some attributes of an account with the given version
+func (g *Gateway) Update(ctx context.Context, uid uuid.UUID, patch data.AccountPatchDto) (data.AccountDto, error) {
+	if err := ctx.Err(); err != nil {
+		return data.AccountDto{}, err
+	}
+	updated, f := g.store.update(uid.String(), patch)
+	if f != nil {
+		return data.AccountDto{}, f.apiError(data.ErrVersionConflict)
+	}
+	return updated, nil
+}
+
+//List a page of accounts
+func (g *Gateway) List(ctx context.Context, params data.ListParams) (data.AccountListDto, error) {
+	if err := ctx.Err(); err != nil {
+		return data.AccountListDto{}, err
+	}
+	page, f := g.store.list(params)
+	if f != nil {
+		return data.AccountListDto{}, f.apiError(nil)
+	}
+	return page, nil
+}
+
+//apiError converts the failure into the error returned by the real gateway.
+//conflict is the sentinel error matching a 409 status in the current operation.
+func (f *failure) apiError(conflict error) *data.APIError {
+	apiErr := &data.APIError{StatusCode: f.status, Message: f.msg, Violations: data.ParseViolations(f.msg)}
+	switch f.status {
+	case http.StatusBadRequest:
+		apiErr.Err = data.ErrValidation
+	case http.StatusNotFound:
+		apiErr.Err = data.ErrNotFound
+	case http.StatusConflict:
+		apiErr.Err = conflict
+	}
+	return apiErr
+}
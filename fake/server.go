@@ -0,0 +1,161 @@
+package fake
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+
+	"github.com/petegabriel/form3_task/data"
+)
+
+//AccountsPath is the path of the accounts resource in the account api.
+const AccountsPath = "/v1/organisation/accounts"
+
+//handler emulates the accounts resource of the account api over http.
+type handler struct {
+	store *store
+}
+
+//NewHandler creates an http.Handler emulating the accounts resource of the account api,
+//served under AccountsPath. Accounts are kept in memory.
+func NewHandler() http.Handler {
+	return &handler{store: newStore()}
+}
+
+//Server is a running instance of the fake account api.
+type Server struct {
+	*httptest.Server
+}
+
+//NewServer starts a fake account api listening on a local address.
+//Callers should call Close when finished to shut it down.
+func NewServer() *Server {
+	return &Server{httptest.NewServer(NewHandler())}
+}
+
+//AccountsURL returns the address of the accounts resource, the value expected in ACCOUNT_API_ADDR.
+func (s *Server) AccountsURL() string {
+	return s.URL + AccountsPath
+}
+
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !strings.HasPrefix(r.URL.Path, AccountsPath) {
+		http.NotFound(w, r)
+		return
+	}
+	id := strings.Trim(strings.TrimPrefix(r.URL.Path, AccountsPath), "/")
+
+	switch {
+	case id == "" && r.Method == http.MethodPost:
+		h.create(w, r)
+	case id == "" && r.Method == http.MethodGet:
+		h.list(w, r)
+	case id == "":
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	case !isUuid(id):
+		writeFailure(w, &failure{http.StatusBadRequest, "id is not a valid uuid"})
+	case r.Method == http.MethodGet:
+		h.get(w, id)
+	case r.Method == http.MethodDelete:
+		h.delete(w, r, id)
+	case r.Method == http.MethodPatch:
+		h.update(w, r, id)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (h *handler) create(w http.ResponseWriter, r *http.Request) {
+	dto := data.AccountDto{}
+	if err := json.NewDecoder(r.Body).Decode(&dto); err != nil {
+		writeFailure(w, &failure{http.StatusBadRequest, fmt.Sprintf("invalid request body: %s", err)})
+		return
+	}
+	created, f := h.store.create(dto)
+	if f != nil {
+		writeFailure(w, f)
+		return
+	}
+	writeJson(w, http.StatusCreated, created)
+}
+
+func (h *handler) get(w http.ResponseWriter, id string) {
+	found, f := h.store.get(id)
+	if f != nil {
+		writeFailure(w, f)
+		return
+	}
+	writeJson(w, http.StatusOK, found)
+}
+
+func (h *handler) delete(w http.ResponseWriter, r *http.Request, id string) {
+	version, err := strconv.Atoi(r.URL.Query().Get("version"))
+	if err != nil {
+		writeFailure(w, &failure{http.StatusBadRequest, "invalid version number"})
+		return
+	}
+	if f := h.store.delete(id, version); f != nil {
+		writeFailure(w, f)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *handler) update(w http.ResponseWriter, r *http.Request, id string) {
+	patch := data.AccountPatchDto{}
+	if err := json.NewDecoder(r.Body).Decode(&patch); err != nil {
+		writeFailure(w, &failure{http.StatusBadRequest, fmt.Sprintf("invalid request body: %s", err)})
+		return
+	}
+	updated, f := h.store.update(id, patch)
+	if f != nil {
+		writeFailure(w, f)
+		return
+	}
+	writeJson(w, http.StatusOK, updated)
+}
+
+func (h *handler) list(w http.ResponseWriter, r *http.Request) {
+	params := data.ListParams{Filter: map[string]string{}}
+	for key, values := range r.URL.Query() {
+		var err error
+		switch {
+		case key == "page[number]":
+			params.PageNumber, err = strconv.Atoi(values[0])
+		case key == "page[size]":
+			params.PageSize, err = strconv.Atoi(values[0])
+		case strings.HasPrefix(key, "filter[") && strings.HasSuffix(key, "]"):
+			params.Filter[key[len("filter["):len(key)-1]] = values[0]
+		}
+		if err != nil || params.PageNumber < 0 || params.PageSize < 0 {
+			writeFailure(w, &failure{http.StatusBadRequest, fmt.Sprintf("invalid value for %s", key)})
+			return
+		}
+	}
+
+	page, f := h.store.list(params)
+	if f != nil {
+		writeFailure(w, f)
+		return
+	}
+	writeJson(w, http.StatusOK, page)
+}
+
+func writeJson(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", data.ContentType)
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+//writeFailure sends the failure in the AccountError format, or without
+//body when there is no message as the account api does.
+func writeFailure(w http.ResponseWriter, f *failure) {
+	if f.msg == "" {
+		w.WriteHeader(f.status)
+		return
+	}
+	writeJson(w, f.status, data.AccountError{ErrorMsg: f.msg})
+}
@@ -0,0 +1,263 @@
+package fake
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/petegabriel/form3_task/data"
+)
+
+//DefaultPageSize is the number of accounts per page when none is requested, as in the account api.
+const DefaultPageSize = 100
+
+//failure describes an error response of the account api.
+type failure struct {
+	status int
+	msg    string
+}
+
+//store keeps the accounts in memory and implements the rules of the account api.
+//It is shared by Gateway and the http handler so both behave the same way.
+type store struct {
+	mu       sync.Mutex
+	accounts map[string]data.AccountDto
+	order    []string
+	now      func() time.Time
+}
+
+func newStore() *store {
+	return &store{
+		accounts: map[string]data.AccountDto{},
+		now:      time.Now,
+	}
+}
+
+func (s *store) create(dto data.AccountDto) (data.AccountDto, *failure) {
+	if violations := validate(dto); len(violations) > 0 {
+		return data.AccountDto{}, validationFailure(violations)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, found := s.accounts[dto.Data.ID]; found {
+		return data.AccountDto{}, &failure{http.StatusConflict, "Account cannot be created as it violates a duplicate constraint"}
+	}
+
+	now := s.timestamp()
+	dto.Data.Version = 0
+	dto.Data.CreatedOn = now
+	dto.Data.ModifiedOn = now
+	s.accounts[dto.Data.ID] = clone(dto)
+	s.order = append(s.order, dto.Data.ID)
+	return clone(dto), nil
+}
+
+func (s *store) get(id string) (data.AccountDto, *failure) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	dto, found := s.accounts[id]
+	if !found {
+		return data.AccountDto{}, &failure{http.StatusNotFound, fmt.Sprintf("record %s does not exist", id)}
+	}
+	return clone(dto), nil
+}
+
+func (s *store) delete(id string, vrs int) *failure {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	dto, found := s.accounts[id]
+	if !found {
+		//the account api answers with an empty body in this case
+		return &failure{http.StatusNotFound, ""}
+	}
+	if dto.Data.Version != vrs {
+		return &failure{http.StatusConflict, "invalid version"}
+	}
+
+	delete(s.accounts, id)
+	for i, other := range s.order {
+		if other == id {
+			s.order = append(s.order[:i], s.order[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+func (s *store) update(id string, patch data.AccountPatchDto) (data.AccountDto, *failure) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	dto, found := s.accounts[id]
+	if !found {
+		return data.AccountDto{}, &failure{http.StatusNotFound, fmt.Sprintf("record %s does not exist", id)}
+	}
+	if dto.Data.Version != patch.Data.Version {
+		return data.AccountDto{}, &failure{http.StatusConflict, "invalid version"}
+	}
+
+	applyPatch(&dto.Data.Attributes, patch.Data.Attributes)
+	if violations := validate(dto); len(violations) > 0 {
+		return data.AccountDto{}, validationFailure(violations)
+	}
+	dto.Data.Version++
+	dto.Data.ModifiedOn = s.timestamp()
+	s.accounts[id] = clone(dto)
+	return clone(dto), nil
+}
+
+func (s *store) list(params data.ListParams) (data.AccountListDto, *failure) {
+	for attr := range params.Filter {
+		if _, known := filters[attr]; !known {
+			return data.AccountListDto{}, &failure{http.StatusBadRequest, fmt.Sprintf("unknown filter %s", attr)}
+		}
+	}
+	size := params.PageSize
+	if size <= 0 {
+		size = DefaultPageSize
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var matching []data.Data
+	for _, id := range s.order {
+		acc := clone(s.accounts[id]).Data
+		if matches(acc.Attributes, params.Filter) {
+			matching = append(matching, acc)
+		}
+	}
+
+	start, end := params.PageNumber*size, (params.PageNumber+1)*size
+	if start > len(matching) {
+		start = len(matching)
+	}
+	if end > len(matching) {
+		end = len(matching)
+	}
+	page := data.AccountListDto{Data: matching[start:end]}
+	if page.Data == nil {
+		page.Data = []data.Data{}
+	}
+	page.Links = links(params, size, len(matching))
+	return page, nil
+}
+
+func (s *store) timestamp() string {
+	return data.FormatTimestamp(s.now())
+}
+
+//clone deep-copies an account so the stored ones share no slice or pointer with the callers.
+//It goes through json, as the accounts sent to and returned by the account api do.
+func clone(dto data.AccountDto) data.AccountDto {
+	body, err := json.Marshal(dto)
+	if err != nil {
+		panic(fmt.Sprintf("fake: cannot copy account %s: %s", dto.Data.ID, err))
+	}
+	var copied data.AccountDto
+	if err := json.Unmarshal(body, &copied); err != nil {
+		panic(fmt.Sprintf("fake: cannot copy account %s: %s", dto.Data.ID, err))
+	}
+	return copied
+}
+
+//filters maps the attributes accepted as filter to their value in an account.
+var filters = map[string]func(data.Attributes) string{
+	"country":        func(a data.Attributes) string { return a.Country },
+	"bank_id":        func(a data.Attributes) string { return a.BankID },
+	"bank_id_code":   func(a data.Attributes) string { return a.BankIDCode },
+	"account_number": func(a data.Attributes) string { return a.AccountNumber },
+	"iban":           func(a data.Attributes) string { return a.Iban },
+}
+
+func matches(attrs data.Attributes, filter map[string]string) bool {
+	for attr, value := range filter {
+		if filters[attr](attrs) != value {
+			return false
+		}
+	}
+	return true
+}
+
+//links builds the pagination links the same way the account api does.
+func links(params data.ListParams, size, total int) data.Links {
+	pageLink := func(number int) string {
+		p := data.ListParams{PageNumber: number, PageSize: size, Filter: params.Filter}
+		return "/v1/organisation/accounts?" + p.Query().Encode()
+	}
+	last := 0
+	if total > 0 {
+		last = (total - 1) / size
+	}
+
+	l := data.Links{
+		First: pageLink(0),
+		Last:  pageLink(last),
+		Self:  pageLink(params.PageNumber),
+	}
+	if params.PageNumber < last {
+		l.Next = pageLink(params.PageNumber + 1)
+	}
+	if params.PageNumber > 0 {
+		l.Prev = pageLink(params.PageNumber - 1)
+	}
+	return l
+}
+
+func applyPatch(attrs *data.Attributes, patch data.AttributesPatch) {
+	setString := func(dst *string, src *string) {
+		if src != nil {
+			*dst = *src
+		}
+	}
+	setBool := func(dst *bool, src *bool) {
+		if src != nil {
+			*dst = *src
+		}
+	}
+	setString(&attrs.BaseCurrency, patch.BaseCurrency)
+	setString(&attrs.AccountNumber, patch.AccountNumber)
+	setString(&attrs.BankID, patch.BankID)
+	setString(&attrs.BankIDCode, patch.BankIDCode)
+	setString(&attrs.Bic, patch.Bic)
+	setString(&attrs.Iban, patch.Iban)
+	setString(&attrs.AccountClassification, patch.AccountClassification)
+	setString(&attrs.SecondaryIdentification, patch.SecondaryIdentification)
+	setBool(&attrs.JointAccount, patch.JointAccount)
+	setBool(&attrs.AccountMatchingOptOut, patch.AccountMatchingOptOut)
+	setBool(&attrs.Switched, patch.Switched)
+	setString(&attrs.Status, patch.Status)
+	setString(&attrs.StatusReason, patch.StatusReason)
+	setString(&attrs.ValidationType, patch.ValidationType)
+	setString(&attrs.ReferenceMask, patch.ReferenceMask)
+	setString(&attrs.AcceptanceQualifier, patch.AcceptanceQualifier)
+	setString(&attrs.ProcessingService, patch.ProcessingService)
+	setString(&attrs.UserDefinedInformation, patch.UserDefinedInformation)
+	setString(&attrs.CustomerID, patch.CustomerID)
+	setString(&attrs.Title, patch.Title)
+	setString(&attrs.FirstName, patch.FirstName)
+	setString(&attrs.BankAccountName, patch.BankAccountName)
+	if patch.UserDefinedData != nil {
+		attrs.UserDefinedData = patch.UserDefinedData
+	}
+	if patch.PrivateIdentification != nil {
+		attrs.PrivateIdentification = patch.PrivateIdentification
+	}
+	if patch.OrganisationIdentification != nil {
+		attrs.OrganisationIdentification = patch.OrganisationIdentification
+	}
+	if patch.Name != nil {
+		attrs.Name = patch.Name
+	}
+	if patch.AlternativeNames != nil {
+		attrs.AlternativeNames = patch.AlternativeNames
+	}
+}
+
+//isUuid reports whether id is a valid uuid in its canonical form.
+func isUuid(id string) bool {
+	_, err := uuid.Parse(id)
+	return err == nil && len(id) == 36
+}
@@ -0,0 +1,99 @@
+package fake
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/petegabriel/form3_task/data"
+)
+
+var (
+	countryPattern  = regexp.MustCompile(`^[A-Z]{2}$`)
+	currencyPattern = regexp.MustCompile(`^[A-Z]{3}$`)
+	bicPattern      = regexp.MustCompile(`^([A-Z]{6}[A-Z0-9]{2}|[A-Z]{6}[A-Z0-9]{5})$`)
+	bankIdPattern   = regexp.MustCompile(`^[A-Z0-9]{0,16}$`)
+	ibanPattern     = regexp.MustCompile(`^[A-Z]{2}[0-9]{2}[A-Z0-9]{0,64}$`)
+)
+
+//validate checks an account the way the account api does, returning
+//one message per violation in the format used by the api.
+func validate(dto data.AccountDto) []string {
+	var v []string
+	d, attrs := dto.Data, dto.Data.Attributes
+
+	if d.Type != "accounts" {
+		v = append(v, "type in body should be one of [accounts]")
+	}
+	if !isUuid(d.ID) {
+		v = append(v, fmt.Sprintf("id in body must be of type uuid: %q", d.ID))
+	}
+	if !isUuid(d.OrganisationID) {
+		v = append(v, fmt.Sprintf("organisation_id in body must be of type uuid: %q", d.OrganisationID))
+	}
+
+	if attrs.Country == "" {
+		v = append(v, "country in body is required")
+	} else if !countryPattern.MatchString(attrs.Country) {
+		v = append(v, fmt.Sprintf("country in body should match '%s'", countryPattern))
+	}
+	if attrs.BaseCurrency != "" && !currencyPattern.MatchString(attrs.BaseCurrency) {
+		v = append(v, fmt.Sprintf("base_currency in body should match '%s'", currencyPattern))
+	}
+	if attrs.Bic != "" && !bicPattern.MatchString(attrs.Bic) {
+		v = append(v, fmt.Sprintf("bic in body should match '%s'", bicPattern))
+	}
+	if !bankIdPattern.MatchString(attrs.BankID) {
+		v = append(v, fmt.Sprintf("bank_id in body should match '%s'", bankIdPattern))
+	}
+	if len(attrs.BankIDCode) > 16 {
+		v = append(v, "bank_id_code in body should be at most 16 chars long")
+	}
+	if attrs.Iban != "" && !ibanPattern.MatchString(attrs.Iban) {
+		v = append(v, fmt.Sprintf("iban in body should match '%s'", ibanPattern))
+	}
+
+	if len(attrs.Name) == 0 {
+		v = append(v, "name in body is required")
+	} else if len(attrs.Name) > 4 {
+		v = append(v, "name in body should have at most 4 items")
+	}
+	v = append(v, checkLines("name", attrs.Name)...)
+	if len(attrs.AlternativeNames) > 3 {
+		v = append(v, "alternative_names in body should have at most 3 items")
+	}
+	v = append(v, checkLines("alternative_names", attrs.AlternativeNames)...)
+
+	switch attrs.AccountClassification {
+	case "", "Personal", "Business":
+	default:
+		v = append(v, "account_classification in body should be one of [Personal Business]")
+	}
+	switch attrs.Status {
+	case "", "pending", "confirmed", "failed", "closed":
+	default:
+		v = append(v, "status in body should be one of [pending confirmed failed closed]")
+	}
+	if len(attrs.SecondaryIdentification) > 140 {
+		v = append(v, "secondary_identification in body should be at most 140 chars long")
+	}
+	return v
+}
+
+func checkLines(field string, lines []string) []string {
+	var v []string
+	for i, line := range lines {
+		if len(line) < 1 {
+			v = append(v, fmt.Sprintf("%s.%d in body should be at least 1 chars long", field, i))
+		} else if len(line) > 140 {
+			v = append(v, fmt.Sprintf("%s.%d in body should be at most 140 chars long", field, i))
+		}
+	}
+	return v
+}
+
+//validationFailure builds the error sent by the account api when validation fails.
+func validationFailure(violations []string) *failure {
+	return &failure{http.StatusBadRequest, "validation failure list:\n" + strings.Join(violations, "\n")}
+}
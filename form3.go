@@ -1,65 +1,118 @@
 package form3_task
 
 import (
-	"errors"
+	"context"
 	"github.com/google/uuid"
-	"github.com/petegabriel/form3_task/data"
-	"log"
-	"strconv"
+	"sync"
 )
 
+var (
+	defaultClientOnce sync.Once
+	defaultClientInst *Client
+)
+
+//defaultClient returns the Client used by the package level functions.
+//It is built on first use so ACCOUNT_API_ADDR is read as late as possible.
+func defaultClient() *Client {
+	defaultClientOnce.Do(func() {
+		defaultClientInst = NewClient()
+	})
+	return defaultClientInst
+}
+
 //CreateAccount creates a new account with the given info.
 //Returns an error if a problem occurs while trying to create the new account.
 func CreateAccount(info *Account) (*Account, error){
-	gate := data.NewGateway()
-	dto := info.ToDto()
-	if acc, err := gate.Create(dto); err != nil {
-		log.Printf(err.Error())
-		return nil, err
-	}else {
-		return NewAccountFromDto(acc), nil
-	}
+	return defaultClient().CreateAccount(info)
+}
+
+//CreateAccountContext is like CreateAccount but the request is bound to ctx.
+func CreateAccountContext(ctx context.Context, info *Account) (*Account, error) {
+	return defaultClient().CreateAccountContext(ctx, info)
+}
+
+//CreateOrGetAccount creates the account or, when an account with the same id already
+//exists with the attributes sent, returns it. Fails with ErrConflictingAccount otherwise.
+func CreateOrGetAccount(info *Account) (*Account, error) {
+	return defaultClient().CreateOrGetAccount(info)
+}
+
+//CreateOrGetAccountContext is like CreateOrGetAccount but the requests are bound to ctx.
+func CreateOrGetAccountContext(ctx context.Context, info *Account) (*Account, error) {
+	return defaultClient().CreateOrGetAccountContext(ctx, info)
 }
 
 //DeleteAccount deletes the account with the given id.
 //Given id must be a valid uuid type.
 //Returns an error if a problem occurs while trying to delete the account with the given id.
 func DeleteAccount(id string, vrs int) error {
-	uid, isUuid := checkUuid(id)
-	if !isUuid {
-		invalidIdErr := errors.New("given id must be a valid uuid type")
-		log.Print(invalidIdErr)
-		return invalidIdErr
-	}
-
-	gate := data.NewGateway()
-	err := gate.Delete(uid, strconv.Itoa(vrs))
-	if err != nil {
-		log.Printf(err.Error())
-		return err
-	}
+	return defaultClient().DeleteAccount(id, vrs)
+}
 
-	return nil
+//DeleteAccountContext is like DeleteAccount but the request is bound to ctx.
+func DeleteAccountContext(ctx context.Context, id string, vrs int) error {
+	return defaultClient().DeleteAccountContext(ctx, id, vrs)
 }
 
 //GetAccount retrieves an account by the given id.
 //Given id must be a valid uuid type.
 //Returns an error if a problem occurs while trying to delete the account with the given id.
 func GetAccount(id string) (*Account, error){
-	uid, isUuid := checkUuid(id)
-	if !isUuid {
-		invalidIdErr := errors.New("given id must be a valid uuid type")
-		log.Print(invalidIdErr)
-		return nil, invalidIdErr
-	}
+	return defaultClient().GetAccount(id)
+}
 
-	gate := data.NewGateway()
-	if found, err := gate.Get(uid); err != nil {
-		log.Print(err)
-		return nil, err
-	}else {
-		return NewAccountFromDto(found), nil
-	}
+//GetAccountContext is like GetAccount but the request is bound to ctx.
+func GetAccountContext(ctx context.Context, id string) (*Account, error) {
+	return defaultClient().GetAccountContext(ctx, id)
+}
+
+//UpdateAccount changes the attributes present in the patch of the account with the given id and version.
+//Returns the account as it is after the change.
+func UpdateAccount(id string, vrs int, patch *AccountPatch) (*Account, error) {
+	return defaultClient().UpdateAccount(id, vrs, patch)
+}
+
+//UpdateAccountContext is like UpdateAccount but the request is bound to ctx.
+func UpdateAccountContext(ctx context.Context, id string, vrs int, patch *AccountPatch) (*Account, error) {
+	return defaultClient().UpdateAccountContext(ctx, id, vrs, patch)
+}
+
+//ListAccounts retrieves a page of accounts according to the given options.
+//Returns an error if a problem occurs while trying to list the accounts.
+func ListAccounts(opts ListOptions) (*AccountPage, error) {
+	return defaultClient().ListAccounts(opts)
+}
+
+//ListAccountsContext is like ListAccounts but the request is bound to ctx.
+func ListAccountsContext(ctx context.Context, opts ListOptions) (*AccountPage, error) {
+	return defaultClient().ListAccountsContext(ctx, opts)
+}
+
+//CreateAccounts creates the given accounts concurrently as set by opts.
+//Results are in the same order as the accounts.
+func CreateAccounts(accounts []*Account, opts BatchOptions) ([]CreateResult, error) {
+	return defaultClient().CreateAccounts(accounts, opts)
+}
+
+//CreateAccountsContext is like CreateAccounts but the requests are bound to ctx.
+func CreateAccountsContext(ctx context.Context, accounts []*Account, opts BatchOptions) ([]CreateResult, error) {
+	return defaultClient().CreateAccountsContext(ctx, accounts, opts)
+}
+
+//DeleteAccounts deletes the given accounts concurrently as set by opts.
+//Results are in the same order as the requests.
+func DeleteAccounts(reqs []DeleteRequest, opts BatchOptions) ([]DeleteResult, error) {
+	return defaultClient().DeleteAccounts(reqs, opts)
+}
+
+//DeleteAccountsContext is like DeleteAccounts but the requests are bound to ctx.
+func DeleteAccountsContext(ctx context.Context, reqs []DeleteRequest, opts BatchOptions) ([]DeleteResult, error) {
+	return defaultClient().DeleteAccountsContext(ctx, reqs, opts)
+}
+
+//IterateAccounts returns an iterator over every account matching the given options.
+func IterateAccounts(ctx context.Context, opts ListOptions) *AccountIterator {
+	return defaultClient().IterateAccounts(ctx, opts)
 }
 
 func checkUuid(id string) (uuid.UUID, bool) {
@@ -1,7 +1,7 @@
 package form3_task
 
 import (
-	"fmt"
+	"errors"
 	"github.com/google/uuid"
 	is2 "github.com/matryer/is"
 	"testing"
@@ -26,7 +26,7 @@ func TestGetAccountUuidNotFound(t *testing.T) {
 	id := getRandomId().String()
 	acc, err := GetAccount(id)
 	is.True(err != nil)
-	is.Equal(err.Error(), fmt.Sprintf("account with uid %s not found", id))
+	is.True(errors.Is(err, ErrNotFound))
 	is.True(acc == nil)
 }
 
@@ -36,13 +36,13 @@ func TestGetWithInvalidUUID(t *testing.T) {
 	acc, err := GetAccount(id)
 	is.True(err != nil)
 	is.True(acc == nil)
-	is.Equal(err.Error(), "given id must be a valid uuid type")
+	is.True(errors.Is(err, ErrInvalidID))
 }
 
 func TestCreateAccount(t *testing.T) {
 	is := is2.New(t)
 
-	country := "GB"
+	country := Country("GB")
 	name := []string{"Samantha Holder"}
 	id := getRandomId()
 	orgId := getRandomId()
@@ -67,7 +67,7 @@ func TestCreateAccount(t *testing.T) {
 
 func TestCreateAccountWithMinimumInfo(t *testing.T) {
 	is := is2.New(t)
-	country := "PT"
+	country := Country("PT")
 	name := []string{"Pedro", "Almeida"}
 	id := getRandomId()
 	orgId := getRandomId()
@@ -85,7 +85,7 @@ func TestCreateAccountWithMinimumInfo(t *testing.T) {
 
 func TestCreateAccountConflict(t *testing.T) {
 	is := is2.New(t)
-	country := "PT"
+	country := Country("PT")
 	name := []string{"Pedro", "Almeida"}
 	id := getRandomId()
 	orgId := getRandomId()
@@ -97,8 +97,7 @@ func TestCreateAccountConflict(t *testing.T) {
 	//same id
 	dto2 := NewAccount(name, country, id, getRandomId())
 	_, err = CreateAccount(dto2)
-	is.True(err != nil)
-	is.True(len(err.Error()) > 0)
+	is.True(errors.Is(err, ErrDuplicate))
 
 	//clean up data
 	err = DeleteAccount(id.String(), 0)
@@ -108,15 +107,14 @@ func TestCreateAccountConflict(t *testing.T) {
 
 func TestCreateAccountWithInvalidParams(t *testing.T) {
 	is := is2.New(t)
-	country := "PT"
+	country := Country("PT")
 	//invalid name
 	name := []string{"Pedro", "", "Almeida"}
 	id := getRandomId()
 	orgId := getRandomId()
 	dto := NewAccount(name, country, id, orgId)
 	acc, err := CreateAccount(dto)
-	is.True(err != nil)
-	is.True(len(err.Error()) > 0)
+	is.True(errors.Is(err, ErrValidation))
 	is.Equal(acc, nil)
 }
 
@@ -125,7 +123,7 @@ func TestDeleteWithInvalidUUID(t *testing.T) {
 	id := "c1-70-41-9a-e21"
 	err := DeleteAccount(id, 0)
 	is.True(err != nil)
-	is.Equal(err.Error(), "given id must be a valid uuid type")
+	is.True(errors.Is(err, ErrInvalidID))
 }
 
 func TestDeleteWithNonexistentUUID(t *testing.T) {
@@ -133,13 +131,13 @@ func TestDeleteWithNonexistentUUID(t *testing.T) {
 	id := getRandomId().String()
 	err := DeleteAccount(id, 0)
 	is.True(err != nil)
-	is.Equal(err.Error(), fmt.Sprintf("account with uuid %s not found", id))
+	is.True(errors.Is(err, ErrNotFound))
 }
 
 func TestDeleteWithInvalidVersion(t *testing.T) {
 	is := is2.New(t)
 
-	country := "GB"
+	country := Country("GB")
 	name := []string{"Peter Devos"}
 	id := getRandomId()
 	orgId := getRandomId()
@@ -149,7 +147,7 @@ func TestDeleteWithInvalidVersion(t *testing.T) {
 
 	err = DeleteAccount(id.String(), 10)
 	is.True(err != nil)
-	is.Equal(err.Error(), "account with specified version not found")
+	is.True(errors.Is(err, ErrVersionConflict))
 
 	//clean up data
 	_ = DeleteAccount(id.String(), 0)
@@ -158,7 +156,7 @@ func TestDeleteWithInvalidVersion(t *testing.T) {
 
 func TestNewAccount(t *testing.T) {
 	is := is2.New(t)
-	country := "PT"
+	country := Country("PT")
 	name := []string{"Pedro", "Almeida"}
 	id := getRandomId()
 	orgId := getRandomId()
@@ -167,7 +165,7 @@ func TestNewAccount(t *testing.T) {
 	is.Equal(accInfo.Id, id)
 	is.Equal(accInfo.OrganisationId, orgId)
 	is.Equal(accInfo.Country, country)
-	is.Equal(accInfo.BaseCurrency, "")
+	is.Equal(accInfo.BaseCurrency, Currency(""))
 	is.Equal(accInfo.AccountNumber, "")
 	is.Equal(accInfo.BankId, "")
 	is.Equal(accInfo.BankIdCode, "")
@@ -192,8 +190,8 @@ func getRandomId() uuid.UUID {
 
 func assertAccountData(is *is2.I, acc *Account, dto *Account) {
 	is.Equal(acc.Id, dto.Id)
-	is.True(len(acc.CreatedOn)>0)
-	is.True(len(acc.ModifiedOn)>0)
+	is.True(!acc.CreatedOn.IsZero())
+	is.True(!acc.ModifiedOn.IsZero())
 	is.Equal(acc.OrganisationId, dto.OrganisationId)
 	is.Equal(acc.Country, dto.Country)
 	is.Equal(acc.BaseCurrency, dto.BaseCurrency)
@@ -209,4 +207,4 @@ func assertAccountData(is *is2.I, acc *Account, dto *Account) {
 	is.Equal(acc.IsAccountMatchingOptOut, dto.IsAccountMatchingOptOut)
 	is.Equal(acc.SecondaryIdentification, dto.SecondaryIdentification)
 	is.Equal(acc.IsSwitched, dto.IsSwitched)
-}
\ No newline at end of file
+}
@@ -0,0 +1,272 @@
+//Package form3otel instruments the form3_task Client with OpenTelemetry: a span and
+//metrics for each operation (create, get, delete, update, list) and for each http
+//attempt made by the gateway, retries included.
+//
+//It lives in its own module so that the client does not depend on OpenTelemetry.
+package form3otel
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	form3 "github.com/petegabriel/form3_task"
+	"github.com/petegabriel/form3_task/data"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+//ScopeName is the name of the tracer and meter of the instrumentation.
+const ScopeName = "github.com/petegabriel/form3_task/form3otel"
+
+//Attributes set on the spans and metrics, besides the http ones of the OpenTelemetry conventions.
+const (
+	OperationKey  = attribute.Key("form3.operation")
+	AccountIdKey  = attribute.Key("form3.account.id")
+	AttemptKey    = attribute.Key("form3.attempt")
+	RetryCountKey = attribute.Key("form3.retry_count")
+	ErrorTypeKey  = attribute.Key("error.type")
+	MethodKey     = attribute.Key("http.request.method")
+	StatusCodeKey = attribute.Key("http.response.status_code")
+	UrlKey        = attribute.Key("url.full")
+)
+
+//Option customizes the Instrumentation built by New.
+type Option func(*config)
+
+type config struct {
+	tracerProvider trace.TracerProvider
+	meterProvider  metric.MeterProvider
+	propagator     propagation.TextMapPropagator
+}
+
+//WithTracerProvider sets the provider of the tracer, the global one by default.
+func WithTracerProvider(tp trace.TracerProvider) Option {
+	return func(c *config) {
+		c.tracerProvider = tp
+	}
+}
+
+//WithMeterProvider sets the provider of the meter, the global one by default.
+func WithMeterProvider(mp metric.MeterProvider) Option {
+	return func(c *config) {
+		c.meterProvider = mp
+	}
+}
+
+//WithPropagator sets the propagator injecting the trace context in the request
+//headers (e.g. traceparent), the global one by default.
+func WithPropagator(p propagation.TextMapPropagator) Option {
+	return func(c *config) {
+		c.propagator = p
+	}
+}
+
+//Instrumentation records the traces and metrics of a Client. Plug it with
+//ClientOptions, or with its Decorator and Middleware methods.
+type Instrumentation struct {
+	tracer     trace.Tracer
+	propagator propagation.TextMapPropagator
+
+	operations      metric.Int64Counter
+	operationTime   metric.Float64Histogram
+	attempts        metric.Int64Counter
+	attemptDuration metric.Float64Histogram
+}
+
+//New creates the instrumentation and its metric instruments:
+//
+//  form3.client.operations          counter of operations, by form3.operation and error.type
+//  form3.client.operation.duration  histogram of the operations latency in seconds, retries included
+//  form3.client.http.attempts       counter of http attempts, by http.request.method, http.response.status_code and error.type
+//  form3.client.http.duration       histogram of the http attempts latency in seconds
+func New(opts ...Option) (*Instrumentation, error) {
+	cfg := &config{
+		tracerProvider: otel.GetTracerProvider(),
+		meterProvider:  otel.GetMeterProvider(),
+		propagator:     otel.GetTextMapPropagator(),
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	in := &Instrumentation{
+		tracer:     cfg.tracerProvider.Tracer(ScopeName),
+		propagator: cfg.propagator,
+	}
+	meter := cfg.meterProvider.Meter(ScopeName)
+	var err error
+	if in.operations, err = meter.Int64Counter("form3.client.operations",
+		metric.WithDescription("Number of account operations made"), metric.WithUnit("{operation}")); err != nil {
+		return nil, err
+	}
+	if in.operationTime, err = meter.Float64Histogram("form3.client.operation.duration",
+		metric.WithDescription("Duration of the account operations, retries included"), metric.WithUnit("s")); err != nil {
+		return nil, err
+	}
+	if in.attempts, err = meter.Int64Counter("form3.client.http.attempts",
+		metric.WithDescription("Number of http requests sent to the account api"), metric.WithUnit("{request}")); err != nil {
+		return nil, err
+	}
+	if in.attemptDuration, err = meter.Float64Histogram("form3.client.http.duration",
+		metric.WithDescription("Duration of the http requests sent to the account api"), metric.WithUnit("s")); err != nil {
+		return nil, err
+	}
+	return in, nil
+}
+
+//ClientOptions returns the options plugging the instrumentation into a Client.
+func (in *Instrumentation) ClientOptions() []form3.Option {
+	return []form3.Option{form3.WithDecorator(in.Decorator), form3.WithMiddleware(in.Middleware)}
+}
+
+//Decorator wraps a gateway so that every operation is recorded in a span and in the metrics.
+func (in *Instrumentation) Decorator(next data.AccountApiGateway) data.AccountApiGateway {
+	return &gateway{next: next, in: in}
+}
+
+type attemptsKey struct{}
+
+//start begins the span of an operation. The returned context counts the http attempts made.
+func (in *Instrumentation) start(ctx context.Context, op string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	ctx, span := in.tracer.Start(ctx, "form3."+op, trace.WithSpanKind(trace.SpanKindInternal),
+		trace.WithAttributes(append(attrs, OperationKey.String(op))...))
+	return context.WithValue(ctx, attemptsKey{}, new(int64)), span
+}
+
+//end records the outcome of an operation started at the given time.
+func (in *Instrumentation) end(ctx context.Context, span trace.Span, op string, start time.Time, err error) {
+	retries := int64(0)
+	if n, ok := ctx.Value(attemptsKey{}).(*int64); ok && atomic.LoadInt64(n) > 1 {
+		retries = atomic.LoadInt64(n) - 1
+	}
+	span.SetAttributes(RetryCountKey.Int64(retries))
+	attrs := []attribute.KeyValue{OperationKey.String(op)}
+	if err != nil {
+		var apiErr *data.APIError
+		if errors.As(err, &apiErr) {
+			span.SetAttributes(StatusCodeKey.Int(apiErr.StatusCode))
+		}
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		attrs = append(attrs, ErrorTypeKey.String(errorType(err)))
+	}
+	span.End()
+
+	set := metric.WithAttributes(attrs...)
+	in.operations.Add(ctx, 1, set)
+	in.operationTime.Record(ctx, time.Since(start).Seconds(), set)
+}
+
+//errorType names the kind of error for the error.type attribute.
+func errorType(err error) string {
+	switch {
+	case errors.Is(err, data.ErrNotFound):
+		return "not_found"
+	case errors.Is(err, data.ErrVersionConflict):
+		return "version_conflict"
+	case errors.Is(err, data.ErrDuplicate):
+		return "duplicate"
+	case errors.Is(err, data.ErrValidation):
+		return "validation"
+	case errors.Is(err, data.ErrUnauthorized), errors.Is(err, data.ErrAuthentication):
+		return "unauthorized"
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return "cancelled"
+	}
+	var apiErr *data.APIError
+	if errors.As(err, &apiErr) {
+		return "api_error"
+	}
+	return "transport"
+}
+
+//gateway records every call made to the gateway it wraps.
+type gateway struct {
+	next data.AccountApiGateway
+	in   *Instrumentation
+}
+
+func (g *gateway) Create(ctx context.Context, dto data.AccountDto) (data.AccountDto, error) {
+	start := time.Now()
+	ctx, span := g.in.start(ctx, "CreateAccount", AccountIdKey.String(dto.Data.ID))
+	acc, err := g.next.Create(ctx, dto)
+	g.in.end(ctx, span, "CreateAccount", start, err)
+	return acc, err
+}
+
+func (g *gateway) Delete(ctx context.Context, uid uuid.UUID, vrs string) error {
+	start := time.Now()
+	ctx, span := g.in.start(ctx, "DeleteAccount", AccountIdKey.String(uid.String()))
+	err := g.next.Delete(ctx, uid, vrs)
+	g.in.end(ctx, span, "DeleteAccount", start, err)
+	return err
+}
+
+func (g *gateway) Get(ctx context.Context, uid uuid.UUID) (data.AccountDto, error) {
+	start := time.Now()
+	ctx, span := g.in.start(ctx, "GetAccount", AccountIdKey.String(uid.String()))
+	acc, err := g.next.Get(ctx, uid)
+	g.in.end(ctx, span, "GetAccount", start, err)
+	return acc, err
+}
+
+func (g *gateway) Update(ctx context.Context, uid uuid.UUID, patch data.AccountPatchDto) (data.AccountDto, error) {
+	start := time.Now()
+	ctx, span := g.in.start(ctx, "UpdateAccount", AccountIdKey.String(uid.String()))
+	acc, err := g.next.Update(ctx, uid, patch)
+	g.in.end(ctx, span, "UpdateAccount", start, err)
+	return acc, err
+}
+
+func (g *gateway) List(ctx context.Context, params data.ListParams) (data.AccountListDto, error) {
+	start := time.Now()
+	ctx, span := g.in.start(ctx, "ListAccounts")
+	page, err := g.next.List(ctx, params)
+	g.in.end(ctx, span, "ListAccounts", start, err)
+	return page, err
+}
+
+//Middleware records every http attempt in a client span, child of the operation span,
+//and injects the trace context in the request headers.
+func (in *Instrumentation) Middleware(next http.RoundTripper) http.RoundTripper {
+	return data.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		ctx := req.Context()
+		attempt := int64(1)
+		if n, ok := ctx.Value(attemptsKey{}).(*int64); ok {
+			attempt = atomic.AddInt64(n, 1)
+		}
+		ctx, span := in.tracer.Start(ctx, req.Method, trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(MethodKey.String(req.Method), UrlKey.String(req.URL.String()), AttemptKey.Int64(attempt)))
+		defer span.End()
+
+		req = req.Clone(ctx)
+		in.propagator.Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+		start := time.Now()
+		resp, err := next.RoundTrip(req)
+		attrs := []attribute.KeyValue{MethodKey.String(req.Method)}
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			attrs = append(attrs, ErrorTypeKey.String("transport"))
+		} else {
+			span.SetAttributes(StatusCodeKey.Int(resp.StatusCode))
+			if resp.StatusCode >= http.StatusBadRequest {
+				span.SetStatus(codes.Error, resp.Status)
+			}
+			attrs = append(attrs, StatusCodeKey.Int(resp.StatusCode))
+		}
+		set := metric.WithAttributes(attrs...)
+		in.attempts.Add(ctx, 1, set)
+		in.attemptDuration.Record(ctx, time.Since(start).Seconds(), set)
+		return resp, err
+	})
+}
@@ -0,0 +1,162 @@
+package form3otel
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/google/uuid"
+	is2 "github.com/matryer/is"
+	form3 "github.com/petegabriel/form3_task"
+	"github.com/petegabriel/form3_task/fake"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+//setup starts a fake account api failing the first request with 503, and a client
+//instrumented with in-memory exporters.
+func setup(t *testing.T) (*form3.Client, *tracetest.InMemoryExporter, *sdkmetric.ManualReader, *int32) {
+	t.Helper()
+	var requests int32
+	var traceparents int32
+	api := fake.NewHandler()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("traceparent") != "" {
+			atomic.AddInt32(&traceparents, 1)
+		}
+		if atomic.AddInt32(&requests, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		api.ServeHTTP(w, r)
+	}))
+	t.Cleanup(srv.Close)
+
+	exporter := tracetest.NewInMemoryExporter()
+	reader := sdkmetric.NewManualReader()
+	in, err := New(
+		WithTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))),
+		WithMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))),
+		WithPropagator(propagation.TraceContext{}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	policy := form3.DefaultRetryPolicy
+	policy.BaseDelay = 0
+	opts := append(in.ClientOptions(), form3.WithBaseURL(srv.URL+fake.AccountsPath), form3.WithRetryPolicy(policy))
+	return form3.NewClient(opts...), exporter, reader, &traceparents
+}
+
+func attr(attrs []attribute.KeyValue, key attribute.Key) (attribute.Value, bool) {
+	for _, kv := range attrs {
+		if kv.Key == key {
+			return kv.Value, true
+		}
+	}
+	return attribute.Value{}, false
+}
+
+func TestSpans(t *testing.T) {
+	is := is2.New(t)
+	client, exporter, _, traceparents := setup(t)
+
+	acc := form3.NewAccount([]string{"Jane Doe"}, "GB", uuid.New(), uuid.New())
+	_, err := client.CreateAccount(acc)
+	is.NoErr(err)
+	_, err = client.GetAccount(acc.Id.String())
+	is.NoErr(err)
+	err = client.DeleteAccount(acc.Id.String(), 3)
+	is.True(errors.Is(err, form3.ErrVersionConflict))
+
+	spans := exporter.GetSpans()
+	var names []string
+	for _, s := range spans {
+		names = append(names, s.Name)
+	}
+	//spans are exported once ended, the attempts before their operation
+	is.Equal(names, []string{"POST", "POST", "form3.CreateAccount", "GET", "form3.GetAccount", "DELETE", "form3.DeleteAccount"})
+	is.Equal(atomic.LoadInt32(traceparents), int32(4))
+
+	create := spans[2]
+	id, _ := attr(create.Attributes, AccountIdKey)
+	is.Equal(id.AsString(), acc.Id.String())
+	retries, _ := attr(create.Attributes, RetryCountKey)
+	is.Equal(retries.AsInt64(), int64(1))
+	for i, s := range spans[:2] {
+		is.Equal(s.Parent.SpanID(), create.SpanContext.SpanID())
+		attempt, _ := attr(s.Attributes, AttemptKey)
+		is.Equal(attempt.AsInt64(), int64(i+1))
+	}
+	status, _ := attr(spans[0].Attributes, StatusCodeKey)
+	is.Equal(status.AsInt64(), int64(http.StatusServiceUnavailable))
+	is.Equal(spans[0].Status.Code, codes.Error)
+	status, _ = attr(spans[1].Attributes, StatusCodeKey)
+	is.Equal(status.AsInt64(), int64(http.StatusCreated))
+
+	get := spans[4]
+	retries, _ = attr(get.Attributes, RetryCountKey)
+	is.Equal(retries.AsInt64(), int64(0))
+	is.Equal(get.Status.Code, codes.Unset)
+
+	del := spans[6]
+	is.Equal(del.Status.Code, codes.Error)
+	status, _ = attr(del.Attributes, StatusCodeKey)
+	is.Equal(status.AsInt64(), int64(http.StatusConflict))
+	is.Equal(len(del.Events), 1) //the error
+}
+
+func TestMetrics(t *testing.T) {
+	is := is2.New(t)
+	client, _, reader, _ := setup(t)
+
+	acc := form3.NewAccount([]string{"Jane Doe"}, "GB", uuid.New(), uuid.New())
+	_, err := client.CreateAccount(acc)
+	is.NoErr(err)
+	_, err = client.GetAccountContext(context.Background(), uuid.NewString())
+	is.True(errors.Is(err, form3.ErrNotFound))
+
+	rm := metricdata.ResourceMetrics{}
+	is.NoErr(reader.Collect(context.Background(), &rm))
+	metrics := map[string]metricdata.Aggregation{}
+	for _, sm := range rm.ScopeMetrics {
+		is.Equal(sm.Scope.Name, ScopeName)
+		for _, m := range sm.Metrics {
+			metrics[m.Name] = m.Data
+		}
+	}
+
+	ops := map[string]int64{}
+	for _, dp := range metrics["form3.client.operations"].(metricdata.Sum[int64]).DataPoints {
+		op, _ := dp.Attributes.Value(OperationKey)
+		errType, _ := dp.Attributes.Value(ErrorTypeKey)
+		ops[op.AsString()+" "+errType.AsString()] += dp.Value
+	}
+	is.Equal(ops, map[string]int64{"CreateAccount ": 1, "GetAccount not_found": 1})
+
+	attempts := map[int64]int64{}
+	for _, dp := range metrics["form3.client.http.attempts"].(metricdata.Sum[int64]).DataPoints {
+		status, _ := dp.Attributes.Value(StatusCodeKey)
+		attempts[status.AsInt64()] += dp.Value
+	}
+	is.Equal(attempts, map[int64]int64{503: 1, 201: 1, 404: 1})
+
+	var recorded uint64
+	for _, dp := range metrics["form3.client.operation.duration"].(metricdata.Histogram[float64]).DataPoints {
+		recorded += dp.Count
+	}
+	is.Equal(recorded, uint64(2))
+	recorded = 0
+	for _, dp := range metrics["form3.client.http.duration"].(metricdata.Histogram[float64]).DataPoints {
+		recorded += dp.Count
+	}
+	is.Equal(recorded, uint64(3))
+}
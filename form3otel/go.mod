@@ -0,0 +1,24 @@
+module github.com/petegabriel/form3_task/form3otel
+
+// OpenTelemetry v1.46 requires go 1.25. The client module keeps its own, older, go
+// directive as it does not depend on this one.
+go 1.25.0
+
+require (
+	github.com/google/uuid v1.6.0
+	github.com/matryer/is v1.4.0
+	github.com/petegabriel/form3_task v0.1.0
+	go.opentelemetry.io/otel v1.46.0
+	go.opentelemetry.io/otel/metric v1.46.0
+	go.opentelemetry.io/otel/sdk v1.46.0
+	go.opentelemetry.io/otel/sdk/metric v1.46.0
+	go.opentelemetry.io/otel/trace v1.46.0
+)
+
+require (
+	github.com/cespare/xxhash/v2 v2.3.0 // indirect
+	github.com/go-logr/logr v1.4.4 // indirect
+	github.com/go-logr/stdr v1.2.2 // indirect
+	go.opentelemetry.io/auto/sdk v1.2.1 // indirect
+	golang.org/x/sys v0.47.0 // indirect
+)
@@ -3,6 +3,7 @@ module github.com/petegabriel/form3_task
 go 1.16
 
 require (
-	github.com/google/uuid v1.2.0 // indirect
-	github.com/matryer/is v1.4.0 // indirect
+	github.com/google/uuid v1.2.0
+	github.com/matryer/is v1.4.0
+	gopkg.in/yaml.v3 v3.0.1
 )
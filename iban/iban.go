@@ -0,0 +1,241 @@
+//Package iban validates, parses and builds International Bank Account Numbers.
+package iban
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var (
+	//ErrUnsupportedCountry is returned for IBANs of countries missing from the registry.
+	ErrUnsupportedCountry = errors.New("iban: country not supported")
+
+	//ErrInvalidLength is returned when the IBAN length does not match its country.
+	ErrInvalidLength = errors.New("iban: invalid length")
+
+	//ErrInvalidFormat is returned when the IBAN has characters not allowed by its country format.
+	ErrInvalidFormat = errors.New("iban: invalid format")
+
+	//ErrInvalidChecksum is returned when the check digits do not match the rest of the IBAN.
+	ErrInvalidChecksum = errors.New("iban: invalid check digits")
+)
+
+//IBAN is a parsed International Bank Account Number.
+type IBAN struct {
+
+	//Country is the ISO 3166-1 alpha-2 code of the country of the account.
+	Country string
+
+	//CheckDigits are the two digits validating the whole IBAN.
+	CheckDigits string
+
+	//BBAN is the Basic Bank Account Number, the country specific part of the IBAN.
+	BBAN string
+
+	//BankCode identifies the bank. In some countries (e.g. GB, NL) it is the bank code of the BIC.
+	BankCode string
+
+	//BranchCode identifies the branch of the bank (e.g. the sort code in GB), if the country has one.
+	BranchCode string
+
+	//AccountNumber is the rest of the BBAN, national check digits included.
+	AccountNumber string
+
+	bicBank bool
+}
+
+//BankId returns the local bank identifier as used in Form3 account data: the branch
+//code when the bank code comes from the BIC (e.g. the sort code in GB), otherwise
+//the bank code followed by the branch code.
+func (i *IBAN) BankId() string {
+	if i.bicBank {
+		return i.BranchCode
+	}
+	return i.BankCode + i.BranchCode
+}
+
+//String returns the IBAN in its electronic format, without spaces.
+func (i *IBAN) String() string {
+	return i.Country + i.CheckDigits + i.BBAN
+}
+
+//Format returns the IBAN in its print format, in groups of four characters.
+func (i *IBAN) Format() string {
+	s := i.String()
+	var b strings.Builder
+	for pos, r := range s {
+		if pos > 0 && pos%4 == 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+//Validate checks the IBAN length and format for its country and its check digits.
+//Spaces are ignored and letters may be in lower case.
+func Validate(s string) error {
+	_, err := Parse(s)
+	return err
+}
+
+//Parse validates the IBAN and splits it into its components.
+func Parse(s string) (*IBAN, error) {
+	s = normalize(s)
+	if len(s) < 4 {
+		return nil, fmt.Errorf("%w: %q is too short", ErrInvalidLength, s)
+	}
+	country, check, bban := s[:2], s[2:4], s[4:]
+	sp, found := registry[country]
+	if !found {
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedCountry, country)
+	}
+	if len(s) != sp.length {
+		return nil, fmt.Errorf("%w: %s IBANs have %d characters, got %d", ErrInvalidLength, country, sp.length, len(s))
+	}
+	if !isDigits(check) || !bbanPattern(sp.bban).MatchString(bban) {
+		return nil, fmt.Errorf("%w: %s IBANs must follow 'kk%s'", ErrInvalidFormat, country, sp.bban)
+	}
+	if mod97(bban+country+check) != 1 {
+		return nil, ErrInvalidChecksum
+	}
+
+	i := &IBAN{
+		Country:       country,
+		CheckDigits:   check,
+		BBAN:          bban,
+		BankCode:      bban[sp.bank[0]:sp.bank[1]],
+		AccountNumber: bban[sp.idEnd():],
+		bicBank:       sp.bicBank,
+	}
+	if sp.branch[1] > 0 {
+		i.BranchCode = bban[sp.branch[0]:sp.branch[1]]
+	}
+	return i, nil
+}
+
+//Build creates the IBAN of an account out of its local details, as found in Form3 account data.
+//bankId is the local bank identifier (e.g. the sort code in GB, the BLZ in DE) and accountNumber
+//is the rest of the BBAN. A numeric account number shorter than expected is padded with zeros.
+//National check digits that are not part of the local account number (e.g. in BE, ES, FR
+//and PT) are computed, or checked when the account number already carries them.
+//bic is only used in countries where the IBAN carries the bank code of the BIC (e.g. GB, NL).
+func Build(country, bic, bankId, accountNumber string) (string, error) {
+	country = strings.ToUpper(country)
+	sp, found := registry[country]
+	if !found {
+		return "", fmt.Errorf("%w: %q", ErrUnsupportedCountry, country)
+	}
+	if sp.bank[0] > 0 {
+		//the BBAN starts with national check characters (e.g. the CIN in IT)
+		return "", fmt.Errorf("%w: %s IBANs cannot be built from the bank id and account number", ErrUnsupportedCountry, country)
+	}
+
+	bankPart := normalize(bankId)
+	if sp.bicBank {
+		bic = normalize(bic)
+		if len(bic) < 4 {
+			return "", fmt.Errorf("%w: a BIC is required to build %s IBANs", ErrInvalidFormat, country)
+		}
+		bankPart = bic[:4] + bankPart
+	}
+	if len(bankPart) != sp.idEnd() {
+		return "", fmt.Errorf("%w: bank id %q does not fit %s IBANs", ErrInvalidFormat, bankId, country)
+	}
+
+	account := normalize(accountNumber)
+	size := sp.length - 4 - sp.idEnd()
+	var given string
+	if sp.checkDigits != nil {
+		//the national check digits are not part of the account number, unless the
+		//whole rest of the BBAN is given
+		from, to := sp.check[0]-sp.idEnd(), sp.check[1]-sp.idEnd()
+		if len(account) == size {
+			given = account[from:to]
+			account = account[:from] + account[to:]
+		}
+		size -= to - from
+	}
+	if len(account) < size && isDigits(account) {
+		account = strings.Repeat("0", size-len(account)) + account
+	}
+	if len(account) != size {
+		return "", fmt.Errorf("%w: account number %q does not fit %s IBANs", ErrInvalidFormat, accountNumber, country)
+	}
+	bban := bankPart + account
+	if sp.checkDigits != nil {
+		check := sp.checkDigits(bankPart, account)
+		if given != "" && given != check {
+			return "", fmt.Errorf("%w: account number %q has wrong national check digits for %s IBANs", ErrInvalidChecksum, accountNumber, country)
+		}
+		at := sp.check[0]
+		bban = bban[:at] + check + bban[at:]
+	}
+	if !bbanPattern(sp.bban).MatchString(bban) {
+		return "", fmt.Errorf("%w: account number %q does not fit %s IBANs", ErrInvalidFormat, accountNumber, country)
+	}
+
+	check := 98 - mod97(bban+country+"00")
+	return fmt.Sprintf("%s%02d%s", country, check, bban), nil
+}
+
+func normalize(s string) string {
+	return strings.ToUpper(strings.Join(strings.Fields(s), ""))
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+//mod97 computes the remainder of the division by 97 of the number obtained
+//replacing each letter of s by two digits (A = 10, B = 11, ..., Z = 35).
+func mod97(s string) int {
+	rem := 0
+	for _, r := range s {
+		switch {
+		case r >= '0' && r <= '9':
+			rem = (rem*10 + int(r-'0')) % 97
+		case r >= 'A' && r <= 'Z':
+			rem = (rem*100 + int(r-'A') + 10) % 97
+		}
+	}
+	return rem
+}
+
+var (
+	patternsMu sync.Mutex
+	patterns   = map[string]*regexp.Regexp{}
+)
+
+//bbanPattern converts a registry format like '4!a6!n8!n' into a regular expression.
+func bbanPattern(format string) *regexp.Regexp {
+	patternsMu.Lock()
+	defer patternsMu.Unlock()
+	if re, found := patterns[format]; found {
+		return re
+	}
+
+	var expr strings.Builder
+	expr.WriteByte('^')
+	for _, m := range formatPart.FindAllStringSubmatch(format, -1) {
+		n, _ := strconv.Atoi(m[1])
+		class := map[string]string{"n": "[0-9]", "a": "[A-Z]", "c": "[A-Z0-9]"}[m[2]]
+		fmt.Fprintf(&expr, "%s{%d}", class, n)
+	}
+	expr.WriteByte('$')
+
+	re := regexp.MustCompile(expr.String())
+	patterns[format] = re
+	return re
+}
+
+var formatPart = regexp.MustCompile(`([0-9]+)!([nac])`)
@@ -0,0 +1,186 @@
+package iban
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+
+	is2 "github.com/matryer/is"
+)
+
+//valid IBANs taken from the examples of the SWIFT IBAN registry
+var examples = []string{
+	"AD1200012030200359100100",
+	"AT611904300234573201",
+	"BE68539007547034",
+	"BG80BNBG96611020345678",
+	"CH9300762011623852957",
+	"CY17002001280000001200527600",
+	"CZ6508000000192000145399",
+	"DE89370400440532013000",
+	"DK5000400440116243",
+	"EE382200221020145685",
+	"ES9121000418450200051332",
+	"FI2112345600000785",
+	"FR1420041010050500013M02606",
+	"GB29NWBK60161331926819",
+	"GR1601101250000000012300695",
+	"HR1210010051863000160",
+	"HU42117730161111101800000000",
+	"IE29AIBK93115212345678",
+	"IS140159260076545510730339",
+	"IT60X0542811101000000123456",
+	"LT121000011101001000",
+	"LU280019400644750000",
+	"LV80BANK0000435195001",
+	"MT84MALT011000012345MTLCAST001S",
+	"NL91ABNA0417164300",
+	"NO9386011117947",
+	"PL61109010140000071219812874",
+	"PT50000201231234567890154",
+	"RO49AAAA1B31007593840000",
+	"SE4550000000058398257466",
+	"SI56263300012039086",
+	"SK3112000000198742637541",
+}
+
+func TestRegistryIsConsistent(t *testing.T) {
+	for country, sp := range registry {
+		t.Run(country, func(t *testing.T) {
+			is := is2.New(t)
+			total := 0
+			for _, m := range formatPart.FindAllStringSubmatch(sp.bban, -1) {
+				n, err := strconv.Atoi(m[1])
+				is.NoErr(err)
+				total += n
+			}
+			is.Equal(total+4, sp.length) //the BBAN format must fill the IBAN
+			is.True(sp.idEnd() <= sp.length-4)
+		})
+	}
+}
+
+func TestValidateExamples(t *testing.T) {
+	for _, s := range examples {
+		t.Run(s, func(t *testing.T) {
+			is2.New(t).NoErr(Validate(s))
+		})
+	}
+}
+
+func TestValidateIgnoresSpacesAndCase(t *testing.T) {
+	is := is2.New(t)
+	is.NoErr(Validate("GB29 NWBK 6016 1331 9268 19"))
+	is.NoErr(Validate("gb29nwbk60161331926819"))
+}
+
+func TestValidateErrors(t *testing.T) {
+	tests := []struct {
+		iban string
+		err  error
+	}{
+		{"GB", ErrInvalidLength},
+		{"ZZ29NWBK60161331926819", ErrUnsupportedCountry},
+		{"GB29NWBK6016133192681", ErrInvalidLength},
+		{"GB29NWBK601613319268190", ErrInvalidLength},
+		{"GB29NWB160161331926819", ErrInvalidFormat},
+		{"GBX9NWBK60161331926819", ErrInvalidFormat},
+		{"GB28NWBK60161331926819", ErrInvalidChecksum},
+		{"GB29NWBK60161331926818", ErrInvalidChecksum},
+	}
+	for _, tt := range tests {
+		t.Run(tt.iban, func(t *testing.T) {
+			is := is2.New(t)
+			err := Validate(tt.iban)
+			is.True(errors.Is(err, tt.err))
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		iban, bankCode, branchCode, account, bankId string
+	}{
+		{"GB29NWBK60161331926819", "NWBK", "601613", "31926819", "601613"},
+		{"DE89370400440532013000", "37040044", "", "0532013000", "37040044"},
+		{"FR1420041010050500013M02606", "20041", "01005", "0500013M02606", "2004101005"},
+		{"ES9121000418450200051332", "2100", "0418", "450200051332", "21000418"},
+		{"IT60X0542811101000000123456", "05428", "11101", "000000123456", "0542811101"},
+		{"NL91ABNA0417164300", "ABNA", "", "0417164300", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.iban, func(t *testing.T) {
+			is := is2.New(t)
+			i, err := Parse(tt.iban)
+			is.NoErr(err)
+			is.Equal(i.Country, tt.iban[:2])
+			is.Equal(i.CheckDigits, tt.iban[2:4])
+			is.Equal(i.BankCode, tt.bankCode)
+			is.Equal(i.BranchCode, tt.branchCode)
+			is.Equal(i.AccountNumber, tt.account)
+			is.Equal(i.BankId(), tt.bankId)
+			is.Equal(i.String(), tt.iban)
+		})
+	}
+}
+
+func TestFormat(t *testing.T) {
+	is := is2.New(t)
+	i, err := Parse("GB29NWBK60161331926819")
+	is.NoErr(err)
+	is.Equal(i.Format(), "GB29 NWBK 6016 1331 9268 19")
+}
+
+func TestBuild(t *testing.T) {
+	tests := []struct {
+		country, bic, bankId, account, want string
+	}{
+		{"GB", "NWBKGB22", "601613", "31926819", "GB29NWBK60161331926819"},
+		{"GB", "NWBKGB22", "400300", "41426819", "GB16NWBK40030041426819"},
+		{"DE", "", "37040044", "532013000", "DE89370400440532013000"},
+		{"FR", "", "2004101005", "0500013M02606", "FR1420041010050500013M02606"},
+		{"NL", "ABNANL2A", "", "417164300", "NL91ABNA0417164300"},
+		{"BE", "", "539", "0075470", "BE68539007547034"},
+		{"BE", "", "539", "75470", "BE68539007547034"},
+		{"BE", "", "539", "007547034", "BE68539007547034"},
+		{"ES", "", "21000418", "0200051332", "ES9121000418450200051332"},
+		{"ES", "", "21000418", "450200051332", "ES9121000418450200051332"},
+		{"PT", "", "00020123", "12345678901", "PT50000201231234567890154"},
+		{"PT", "", "00020123", "1234567890154", "PT50000201231234567890154"},
+		{"FR", "", "2004101005", "0500013M026", "FR1420041010050500013M02606"},
+		{"MC", "", "1122200001", "01234567890", "MC5811222000010123456789030"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			is := is2.New(t)
+			got, err := Build(tt.country, tt.bic, tt.bankId, tt.account)
+			is.NoErr(err)
+			is.Equal(got, tt.want)
+			is.NoErr(Validate(got))
+		})
+	}
+}
+
+func TestBuildErrors(t *testing.T) {
+	tests := []struct {
+		name, country, bic, bankId, account string
+		err                                 error
+	}{
+		{"unknown country", "ZZ", "", "1234", "5678", ErrUnsupportedCountry},
+		{"national check first", "IT", "", "0542811101", "000000123456", ErrUnsupportedCountry},
+		{"missing bic", "GB", "", "601613", "31926819", ErrInvalidFormat},
+		{"short bank id", "DE", "", "3704004", "532013000", ErrInvalidFormat},
+		{"long account", "DE", "", "37040044", "05320130001", ErrInvalidFormat},
+		{"letters in account", "DE", "", "37040044", "05320X3000", ErrInvalidFormat},
+		{"wrong national check", "BE", "", "539", "007547035", ErrInvalidChecksum},
+		{"wrong control digits", "ES", "", "21000418", "540200051332", ErrInvalidChecksum},
+		{"long national account", "PT", "", "00020123", "123456789012", ErrInvalidFormat},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			is := is2.New(t)
+			_, err := Build(tt.country, tt.bic, tt.bankId, tt.account)
+			is.True(errors.Is(err, tt.err))
+		})
+	}
+}
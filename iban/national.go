@@ -0,0 +1,61 @@
+package iban
+
+import (
+	"fmt"
+	"strings"
+)
+
+//belgianCheck computes the two check digits ending Belgian BBANs: the remainder
+//of the division by 97 of the bank code and account number, 97 instead of 0.
+func belgianCheck(id, account string) string {
+	rem := mod97(id + account)
+	if rem == 0 {
+		rem = 97
+	}
+	return fmt.Sprintf("%02d", rem)
+}
+
+//spanishCheck computes the two control digits (DC) of Spanish BBANs, the first one
+//validating the bank and branch codes and the second one the account number.
+func spanishCheck(id, account string) string {
+	return weighted11("00"+id) + weighted11(account)
+}
+
+func weighted11(s string) string {
+	weights := []int{1, 2, 4, 8, 5, 10, 9, 7, 3, 6}
+	sum := 0
+	for i, r := range s {
+		if i < len(weights) && r >= '0' && r <= '9' {
+			sum += int(r-'0') * weights[i]
+		}
+	}
+	switch d := 11 - sum%11; d {
+	case 11:
+		return "0"
+	case 10:
+		return "1"
+	default:
+		return fmt.Sprint(d)
+	}
+}
+
+//nibCheck computes the two check digits ending Portuguese BBANs (the NIB).
+func nibCheck(id, account string) string {
+	return fmt.Sprintf("%02d", 98-mod97(id+account+"00"))
+}
+
+//ribKey computes the key ending French and Monegasque BBANs (the clé RIB), letters
+//of the account number standing for a digit (A and J = 1, B, K and S = 2, ...).
+func ribKey(id, account string) string {
+	digits := strings.Map(func(r rune) rune {
+		if r >= 'A' && r <= 'Z' {
+			i := r - 'A'
+			if i >= 'S'-'A' {
+				i++
+			}
+			return '1' + i%9
+		}
+		return r
+	}, id+account)
+	return fmt.Sprintf("%02d", 97-mod97(digits+"00"))
+}
@@ -0,0 +1,101 @@
+package iban
+
+//spec describes the structure of the IBANs of a country, as published in the SWIFT IBAN registry.
+type spec struct {
+
+	//length of the whole IBAN.
+	length int
+
+	//bban is the format of the BBAN (the part following the check digits), e.g. '4!a6!n8!n'.
+	bban string
+
+	//bank and branch are the positions [start, end) of the bank and branch
+	//identifiers inside the BBAN. branch is zero when the country has none.
+	bank, branch [2]int
+
+	//bicBank is set when the bank identifier is the bank code of the BIC (e.g. GB, NL).
+	bicBank bool
+
+	//check is the position [start, end) of the national check digits inside the BBAN
+	//when they are not part of the local account number (e.g. BE, ES, FR, PT).
+	check [2]int
+
+	//checkDigits computes the national check digits out of the bank and branch
+	//identifiers and the account number. It is nil when check is zero.
+	checkDigits func(id, account string) string
+}
+
+//registry lists the countries which IBANs are supported.
+var registry = map[string]spec{
+	"AD": {length: 24, bban: "4!n4!n12!c", bank: [2]int{0, 4}, branch: [2]int{4, 8}},
+	"AE": {length: 23, bban: "3!n16!n", bank: [2]int{0, 3}},
+	"AL": {length: 28, bban: "8!n16!c", bank: [2]int{0, 3}, branch: [2]int{3, 8}},
+	"AT": {length: 20, bban: "5!n11!n", bank: [2]int{0, 5}},
+	"AZ": {length: 28, bban: "4!a20!c", bank: [2]int{0, 4}, bicBank: true},
+	"BA": {length: 20, bban: "3!n3!n8!n2!n", bank: [2]int{0, 3}, branch: [2]int{3, 6}},
+	"BE": {length: 16, bban: "3!n7!n2!n", bank: [2]int{0, 3},
+		check: [2]int{10, 12}, checkDigits: belgianCheck},
+	"BG": {length: 22, bban: "4!a4!n2!n8!c", bank: [2]int{0, 4}, branch: [2]int{4, 8}, bicBank: true},
+	"BH": {length: 22, bban: "4!a14!c", bank: [2]int{0, 4}, bicBank: true},
+	"CH": {length: 21, bban: "5!n12!c", bank: [2]int{0, 5}},
+	"CY": {length: 28, bban: "3!n5!n16!c", bank: [2]int{0, 3}, branch: [2]int{3, 8}},
+	"CZ": {length: 24, bban: "4!n6!n10!n", bank: [2]int{0, 4}},
+	"DE": {length: 22, bban: "8!n10!n", bank: [2]int{0, 8}},
+	"DK": {length: 18, bban: "4!n9!n1!n", bank: [2]int{0, 4}},
+	"EE": {length: 20, bban: "2!n14!n", bank: [2]int{0, 2}},
+	"ES": {length: 24, bban: "4!n4!n1!n1!n10!n", bank: [2]int{0, 4}, branch: [2]int{4, 8},
+		check: [2]int{8, 10}, checkDigits: spanishCheck},
+	"FI": {length: 18, bban: "3!n11!n", bank: [2]int{0, 3}},
+	"FO": {length: 18, bban: "4!n9!n1!n", bank: [2]int{0, 4}},
+	"FR": {length: 27, bban: "5!n5!n11!c2!n", bank: [2]int{0, 5}, branch: [2]int{5, 10},
+		check: [2]int{21, 23}, checkDigits: ribKey},
+	"GB": {length: 22, bban: "4!a6!n8!n", bank: [2]int{0, 4}, branch: [2]int{4, 10}, bicBank: true},
+	"GE": {length: 22, bban: "2!a16!n", bank: [2]int{0, 2}},
+	"GI": {length: 23, bban: "4!a15!c", bank: [2]int{0, 4}, bicBank: true},
+	"GL": {length: 18, bban: "4!n9!n1!n", bank: [2]int{0, 4}},
+	"GR": {length: 27, bban: "3!n4!n16!c", bank: [2]int{0, 3}, branch: [2]int{3, 7}},
+	"HR": {length: 21, bban: "7!n10!n", bank: [2]int{0, 7}},
+	"HU": {length: 28, bban: "3!n4!n1!n15!n1!n", bank: [2]int{0, 3}, branch: [2]int{3, 7}},
+	"IE": {length: 22, bban: "4!a6!n8!n", bank: [2]int{0, 4}, branch: [2]int{4, 10}, bicBank: true},
+	"IL": {length: 23, bban: "3!n3!n13!n", bank: [2]int{0, 3}, branch: [2]int{3, 6}},
+	"IS": {length: 26, bban: "4!n2!n6!n10!n", bank: [2]int{0, 2}, branch: [2]int{2, 4}},
+	"IT": {length: 27, bban: "1!a5!n5!n12!c", bank: [2]int{1, 6}, branch: [2]int{6, 11}},
+	"KW": {length: 30, bban: "4!a22!c", bank: [2]int{0, 4}, bicBank: true},
+	"KZ": {length: 20, bban: "3!n13!c", bank: [2]int{0, 3}},
+	"LB": {length: 28, bban: "4!n20!c", bank: [2]int{0, 4}},
+	"LI": {length: 21, bban: "5!n12!c", bank: [2]int{0, 5}},
+	"LT": {length: 20, bban: "5!n11!n", bank: [2]int{0, 5}},
+	"LU": {length: 20, bban: "3!n13!c", bank: [2]int{0, 3}},
+	"LV": {length: 21, bban: "4!a13!c", bank: [2]int{0, 4}, bicBank: true},
+	"MC": {length: 27, bban: "5!n5!n11!c2!n", bank: [2]int{0, 5}, branch: [2]int{5, 10},
+		check: [2]int{21, 23}, checkDigits: ribKey},
+	"MD": {length: 24, bban: "2!c18!c", bank: [2]int{0, 2}},
+	"ME": {length: 22, bban: "3!n13!n2!n", bank: [2]int{0, 3}},
+	"MK": {length: 19, bban: "3!n10!c2!n", bank: [2]int{0, 3}},
+	"MT": {length: 31, bban: "4!a5!n18!c", bank: [2]int{0, 4}, branch: [2]int{4, 9}, bicBank: true},
+	"NL": {length: 18, bban: "4!a10!n", bank: [2]int{0, 4}, bicBank: true},
+	"NO": {length: 15, bban: "4!n6!n1!n", bank: [2]int{0, 4}},
+	"PL": {length: 28, bban: "8!n16!n", bank: [2]int{0, 8}},
+	"PT": {length: 25, bban: "4!n4!n11!n2!n", bank: [2]int{0, 4}, branch: [2]int{4, 8},
+		check: [2]int{19, 21}, checkDigits: nibCheck},
+	"QA": {length: 29, bban: "4!a21!c", bank: [2]int{0, 4}, bicBank: true},
+	"RO": {length: 24, bban: "4!a16!c", bank: [2]int{0, 4}, bicBank: true},
+	"RS": {length: 22, bban: "3!n13!n2!n", bank: [2]int{0, 3}},
+	"SA": {length: 24, bban: "2!n18!c", bank: [2]int{0, 2}},
+	"SE": {length: 24, bban: "3!n16!n1!n", bank: [2]int{0, 3}},
+	"SI": {length: 19, bban: "5!n8!n2!n", bank: [2]int{0, 5}},
+	"SK": {length: 24, bban: "4!n6!n10!n", bank: [2]int{0, 4}},
+	"SM": {length: 27, bban: "1!a5!n5!n12!c", bank: [2]int{1, 6}, branch: [2]int{6, 11}},
+	"TN": {length: 24, bban: "2!n3!n13!n2!n", bank: [2]int{0, 2}, branch: [2]int{2, 5}},
+	"TR": {length: 26, bban: "5!n1!n16!c", bank: [2]int{0, 5}},
+	"UA": {length: 29, bban: "6!n19!c", bank: [2]int{0, 6}},
+	"XK": {length: 20, bban: "4!n10!n2!n", bank: [2]int{0, 2}, branch: [2]int{2, 4}},
+}
+
+//idEnd returns the position in the BBAN where the bank and branch identifiers end.
+func (s spec) idEnd() int {
+	if s.branch[1] > 0 {
+		return s.branch[1]
+	}
+	return s.bank[1]
+}
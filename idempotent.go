@@ -0,0 +1,84 @@
+package form3_task
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/petegabriel/form3_task/data"
+)
+
+//FieldDiff is an attribute which differs between the account sent and the one found.
+type FieldDiff = data.FieldDiff
+
+//ConflictError is returned by CreateOrGetAccount when an account with the same id
+//already exists with different attributes. It matches ErrConflictingAccount.
+type ConflictError struct {
+
+	//Existing is the account found.
+	Existing *Account
+
+	//Diff lists the attributes which differ, sorted by name.
+	Diff []FieldDiff
+}
+
+func (e *ConflictError) Error() string {
+	diffs := make([]string, len(e.Diff))
+	for i, d := range e.Diff {
+		diffs[i] = d.String()
+	}
+	return fmt.Sprintf("%s: %s", ErrConflictingAccount, strings.Join(diffs, "; "))
+}
+
+//Unwrap returns ErrConflictingAccount.
+func (e *ConflictError) Unwrap() error {
+	return ErrConflictingAccount
+}
+
+//CreateOrGetAccount creates the account like CreateAccount but, when an account with
+//the same id already exists, returns it instead of failing as long as it holds the
+//attributes sent. This makes retrying a creation safe. Attributes left empty in info
+//are not compared, as the api may fill them (e.g. account_number, iban).
+//Returns a *ConflictError listing the differences when the existing account does not match.
+func (c *Client) CreateOrGetAccount(info *Account) (*Account, error) {
+	return c.CreateOrGetAccountContext(context.Background(), info)
+}
+
+//CreateOrGetAccountContext is like CreateOrGetAccount but the requests are bound to ctx.
+func (c *Client) CreateOrGetAccountContext(ctx context.Context, info *Account) (*Account, error) {
+	created, err := c.create(ctx, info)
+	if err == nil {
+		return c.decode(ctx, "create or get account", created)
+	}
+	if !errors.Is(err, ErrDuplicate) {
+		return nil, c.failed(ctx, "create or get account", info.Id.String(), err)
+	}
+
+	existing, err := c.GetAccountContext(ctx, info.Id.String())
+	if err != nil {
+		return nil, err
+	}
+	diff, err := diffAccounts(info, existing)
+	if err != nil {
+		return nil, c.failed(ctx, "create or get account", info.Id.String(), err)
+	}
+	if len(diff) > 0 {
+		return nil, c.failed(ctx, "create or get account", info.Id.String(), &ConflictError{Existing: existing, Diff: diff})
+	}
+	c.logger.Log(ctx, LevelInfo, "account already exists", Field{Key: "account_id", Value: info.Id.String()})
+	return existing, nil
+}
+
+//diffAccounts compares the attributes set in sent with those of existing, see data.DiffAccounts.
+func diffAccounts(sent, existing *Account) ([]FieldDiff, error) {
+	sentDto, err := sent.ToDto()
+	if err != nil {
+		return nil, err
+	}
+	existingDto, err := existing.ToDto()
+	if err != nil {
+		return nil, err
+	}
+	return data.DiffAccounts(sentDto, existingDto)
+}
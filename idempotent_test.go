@@ -0,0 +1,111 @@
+package form3_task
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	is2 "github.com/matryer/is"
+	"github.com/petegabriel/form3_task/fake"
+)
+
+func TestCreateOrGetAccount(t *testing.T) {
+	is := is2.New(t)
+	srv := fake.NewServer()
+	defer srv.Close()
+	client := NewClient(WithBaseURL(srv.AccountsURL()))
+
+	acc := NewAccount([]string{"Jane Doe"}, "GB", getRandomId(), getRandomId())
+	acc.BankId = "400300"
+	acc.Bic = "NWBKGB22"
+	created, err := client.CreateOrGetAccount(acc)
+	is.NoErr(err)
+	is.Equal(created.Id, acc.Id)
+
+	//the same account again, e.g. from a retried job
+	again, err := client.CreateOrGetAccount(acc)
+	is.NoErr(err)
+	is.Equal(again, created)
+
+	//attributes left empty are not compared
+	partial := NewAccount([]string{"Jane Doe"}, "GB", acc.Id, acc.OrganisationId)
+	_, err = client.CreateOrGetAccount(partial)
+	is.NoErr(err)
+}
+
+func TestCreateOrGetAccountConflict(t *testing.T) {
+	is := is2.New(t)
+	srv := fake.NewServer()
+	defer srv.Close()
+	client := NewClient(WithBaseURL(srv.AccountsURL()))
+
+	acc := NewAccount([]string{"Jane Doe"}, "GB", getRandomId(), getRandomId())
+	acc.BankId = "400300"
+	_, err := client.CreateAccount(acc)
+	is.NoErr(err)
+
+	other := *acc
+	other.BankId = "400301"
+	other.Name = []string{"Jane Doe", "Jane Marie Doe"}
+	other.IsJointAccount = true
+	_, err = client.CreateOrGetAccount(&other)
+
+	is.True(errors.Is(err, ErrConflictingAccount))
+	var conflict *ConflictError
+	is.True(errors.As(err, &conflict))
+	is.Equal(conflict.Existing.Id, acc.Id)
+	is.Equal(len(conflict.Diff), 3)
+	is.Equal(conflict.Diff[0].Field, "bank_id")
+	is.Equal(conflict.Diff[0].Sent, "400301")
+	is.Equal(conflict.Diff[0].Existing, "400300")
+	is.Equal(conflict.Diff[1].Field, "joint_account")
+	is.Equal(conflict.Diff[2].Field, "name")
+	is.True(strings.Contains(err.Error(), `bank_id: sent "400301", found "400300"`))
+}
+
+func TestCreateOrGetAccountConflictAfterRetry(t *testing.T) {
+	is := is2.New(t)
+	api := fake.NewHandler()
+	var posts int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost && atomic.AddInt32(&posts, 1) == 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		api.ServeHTTP(w, r)
+	}))
+	defer srv.Close()
+	policy := DefaultRetryPolicy
+	policy.BaseDelay = time.Millisecond
+	client := NewClient(WithBaseURL(srv.URL+fake.AccountsPath), WithRetryPolicy(policy))
+
+	acc := NewAccount([]string{"Jane Doe"}, "GB", getRandomId(), getRandomId())
+	acc.BankId = "400300"
+	_, err := client.CreateAccount(acc)
+	is.NoErr(err)
+
+	//the first attempt fails with a 503, the retry with a 409 for an account that is not the one sent
+	other := *acc
+	other.BankId = "400301"
+	_, err = client.CreateOrGetAccount(&other)
+
+	var conflict *ConflictError
+	is.True(errors.As(err, &conflict))
+	is.Equal(conflict.Diff[0].Field, "bank_id")
+	is.Equal(atomic.LoadInt32(&posts), int32(3))
+}
+
+func TestCreateOrGetAccountOtherErrors(t *testing.T) {
+	is := is2.New(t)
+	gate := &stubGateway{}
+	client := NewClient(WithGateway(gate))
+
+	_, err := client.CreateOrGetAccount(NewAccount([]string{""}, "GB", getRandomId(), getRandomId()))
+	is.True(errors.Is(err, ErrValidation))
+	is.Equal(gate.createCalls, 0)
+	is.Equal(gate.getCalls, 0)
+}
@@ -0,0 +1,91 @@
+//Package iso gives access to the ISO 3166-1 alpha-2 country codes and
+//ISO 4217 currency codes, embedded in the binary as csv tables.
+package iso
+
+import (
+	_ "embed"
+	"encoding/csv"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+//go:embed countries.csv
+var countriesCsv string
+
+//go:embed currencies.csv
+var currenciesCsv string
+
+//CurrencyInfo describes an ISO 4217 currency.
+type CurrencyInfo struct {
+
+	//Code is the three letters code of the currency.
+	Code string
+
+	//MinorUnits is the number of digits after the decimal separator,
+	//or -1 when it does not apply (e.g. precious metals).
+	MinorUnits int
+
+	//Name of the currency in English.
+	Name string
+}
+
+var (
+	//countries maps each country code to its default currency code, if any.
+	countries = map[string]string{}
+
+	currencies = map[string]CurrencyInfo{}
+)
+
+func init() {
+	for _, rec := range readCsv(countriesCsv, 2) {
+		countries[rec[0]] = rec[1]
+	}
+	for _, rec := range readCsv(currenciesCsv, 3) {
+		units := -1
+		if rec[1] != "" {
+			var err error
+			if units, err = strconv.Atoi(rec[1]); err != nil {
+				panic(fmt.Sprintf("iso: invalid minor units for %s: %s", rec[0], err))
+			}
+		}
+		currencies[rec[0]] = CurrencyInfo{Code: rec[0], MinorUnits: units, Name: rec[2]}
+	}
+}
+
+//readCsv parses one of the embedded tables, which are known to be well formed.
+func readCsv(content string, fields int) [][]string {
+	r := csv.NewReader(strings.NewReader(content))
+	r.Comment = '#'
+	r.FieldsPerRecord = fields
+	records, err := r.ReadAll()
+	if err != nil {
+		panic(fmt.Sprintf("iso: invalid embedded table: %s", err))
+	}
+	return records
+}
+
+//IsCountry reports whether code is an assigned ISO 3166-1 alpha-2 country code.
+func IsCountry(code string) bool {
+	_, found := countries[code]
+	return found
+}
+
+//IsCurrency reports whether code is an active ISO 4217 currency code.
+func IsCurrency(code string) bool {
+	_, found := currencies[code]
+	return found
+}
+
+//Currency returns the details of the currency with the given code.
+func Currency(code string) (CurrencyInfo, bool) {
+	info, found := currencies[code]
+	return info, found
+}
+
+//DefaultCurrency returns the code of the currency in use in the given country.
+//Returns false if the country is unknown or has no currency of its own (e.g. Antarctica).
+func DefaultCurrency(country string) (string, bool) {
+	code := countries[country]
+	return code, code != ""
+}
@@ -0,0 +1,123 @@
+package form3_task
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/petegabriel/form3_task/internal/iso"
+)
+
+//Country is an ISO 3166-1 alpha-2 country code (e.g. 'GB').
+//Its JSON representation is the code itself.
+type Country string
+
+//NewCountry returns the country with the given code, in upper or lower case.
+//Returns an error matching ErrInvalidCountry if the code is not assigned in ISO 3166-1.
+func NewCountry(code string) (Country, error) {
+	c := Country(strings.ToUpper(strings.TrimSpace(code)))
+	if !c.IsValid() {
+		return "", fmt.Errorf("%w: %q", ErrInvalidCountry, code)
+	}
+	return c, nil
+}
+
+//IsValid reports whether the country is an assigned ISO 3166-1 alpha-2 code.
+func (c Country) IsValid() bool {
+	return iso.IsCountry(string(c))
+}
+
+//DefaultCurrency returns the currency in use in the country.
+//Returns false if the country is unknown or has no currency of its own (e.g. Antarctica).
+func (c Country) DefaultCurrency() (Currency, bool) {
+	code, found := iso.DefaultCurrency(string(c))
+	return Currency(code), found
+}
+
+func (c Country) String() string {
+	return string(c)
+}
+
+//MarshalText writes the country code, so the country is a plain string in JSON.
+func (c Country) MarshalText() ([]byte, error) {
+	return []byte(c), nil
+}
+
+//UnmarshalText reads a country code, failing if it is not assigned in ISO 3166-1.
+//An empty value gives the empty country.
+func (c *Country) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*c = ""
+		return nil
+	}
+	parsed, err := NewCountry(string(text))
+	if err != nil {
+		return err
+	}
+	*c = parsed
+	return nil
+}
+
+//Currency is an ISO 4217 currency code (e.g. 'GBP').
+//Its JSON representation is the code itself.
+type Currency string
+
+//NewCurrency returns the currency with the given code, in upper or lower case.
+//Returns an error matching ErrInvalidCurrency if the code is not an active ISO 4217 code.
+func NewCurrency(code string) (Currency, error) {
+	c := Currency(strings.ToUpper(strings.TrimSpace(code)))
+	if !c.IsValid() {
+		return "", fmt.Errorf("%w: %q", ErrInvalidCurrency, code)
+	}
+	return c, nil
+}
+
+//DefaultCurrency returns the currency in use in the given country.
+//Returns false if the country is unknown or has no currency of its own.
+func DefaultCurrency(country Country) (Currency, bool) {
+	return country.DefaultCurrency()
+}
+
+//IsValid reports whether the currency is an active ISO 4217 code.
+func (c Currency) IsValid() bool {
+	return iso.IsCurrency(string(c))
+}
+
+//Name returns the English name of the currency, or an empty string if it is not valid.
+func (c Currency) Name() string {
+	info, _ := iso.Currency(string(c))
+	return info.Name
+}
+
+//MinorUnits returns the number of digits after the decimal separator (e.g. 2 for GBP, 0 for JPY).
+//Returns -1 if it does not apply (e.g. precious metals) or the currency is not valid.
+func (c Currency) MinorUnits() int {
+	info, found := iso.Currency(string(c))
+	if !found {
+		return -1
+	}
+	return info.MinorUnits
+}
+
+func (c Currency) String() string {
+	return string(c)
+}
+
+//MarshalText writes the currency code, so the currency is a plain string in JSON.
+func (c Currency) MarshalText() ([]byte, error) {
+	return []byte(c), nil
+}
+
+//UnmarshalText reads a currency code, failing if it is not an active ISO 4217 code.
+//An empty value gives the empty currency.
+func (c *Currency) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*c = ""
+		return nil
+	}
+	parsed, err := NewCurrency(string(text))
+	if err != nil {
+		return err
+	}
+	*c = parsed
+	return nil
+}
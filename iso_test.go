@@ -0,0 +1,124 @@
+package form3_task
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	is2 "github.com/matryer/is"
+)
+
+func TestNewCountry(t *testing.T) {
+	tests := []struct {
+		code string
+		want Country
+		err  error
+	}{
+		{"GB", "GB", nil},
+		{"pt", "PT", nil},
+		{" de ", "DE", nil},
+		{"UK", "", ErrInvalidCountry},
+		{"GBR", "", ErrInvalidCountry},
+		{"", "", ErrInvalidCountry},
+	}
+	for _, tt := range tests {
+		t.Run(tt.code, func(t *testing.T) {
+			is := is2.New(t)
+			c, err := NewCountry(tt.code)
+			is.True(errors.Is(err, tt.err))
+			is.Equal(c, tt.want)
+		})
+	}
+}
+
+func TestNewCurrency(t *testing.T) {
+	tests := []struct {
+		code string
+		want Currency
+		err  error
+	}{
+		{"GBP", "GBP", nil},
+		{"eur", "EUR", nil},
+		{"GBX", "", ErrInvalidCurrency},
+		{"EU", "", ErrInvalidCurrency},
+		{"", "", ErrInvalidCurrency},
+	}
+	for _, tt := range tests {
+		t.Run(tt.code, func(t *testing.T) {
+			is := is2.New(t)
+			c, err := NewCurrency(tt.code)
+			is.True(errors.Is(err, tt.err))
+			is.Equal(c, tt.want)
+		})
+	}
+}
+
+func TestCurrencyDetails(t *testing.T) {
+	is := is2.New(t)
+	is.Equal(Currency("GBP").Name(), "Pound Sterling")
+	is.Equal(Currency("GBP").MinorUnits(), 2)
+	is.Equal(Currency("JPY").MinorUnits(), 0)
+	is.Equal(Currency("XAU").MinorUnits(), -1)
+	is.Equal(Currency("XXY").MinorUnits(), -1)
+	is.Equal(Currency("XXY").Name(), "")
+}
+
+func TestDefaultCurrency(t *testing.T) {
+	tests := []struct {
+		country Country
+		want    Currency
+		found   bool
+	}{
+		{"GB", "GBP", true},
+		{"PT", "EUR", true},
+		{"US", "USD", true},
+		{"CH", "CHF", true},
+		{"AQ", "", false},
+		{"UK", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.country), func(t *testing.T) {
+			is := is2.New(t)
+			cur, found := DefaultCurrency(tt.country)
+			is.Equal(found, tt.found)
+			is.Equal(cur, tt.want)
+		})
+	}
+}
+
+func TestCountryAndCurrencyJson(t *testing.T) {
+	is := is2.New(t)
+	type holder struct {
+		Country  Country  `json:"country"`
+		Currency Currency `json:"currency"`
+	}
+
+	body, err := json.Marshal(holder{Country: "GB", Currency: "GBP"})
+	is.NoErr(err)
+	is.Equal(string(body), `{"country":"GB","currency":"GBP"}`)
+
+	var h holder
+	is.NoErr(json.Unmarshal([]byte(`{"country":"pt","currency":"eur"}`), &h))
+	is.Equal(h, holder{Country: "PT", Currency: "EUR"})
+	is.NoErr(json.Unmarshal([]byte(`{"country":"","currency":""}`), &h))
+	is.Equal(h, holder{})
+
+	is.True(errors.Is(json.Unmarshal([]byte(`{"country":"UK"}`), &h), ErrInvalidCountry))
+	is.True(errors.Is(json.Unmarshal([]byte(`{"currency":"GBX"}`), &h), ErrInvalidCurrency))
+}
+
+func TestCountryAndCurrencyOnTheWire(t *testing.T) {
+	is := is2.New(t)
+	acc := NewAccount([]string{"Jane"}, "GB", getRandomId(), getRandomId())
+	acc.BaseCurrency = "GBP"
+
+	dto, err := acc.ToDto()
+	is.NoErr(err)
+	is.Equal(dto.Data.Attributes.Country, "GB")
+	is.Equal(dto.Data.Attributes.BaseCurrency, "GBP")
+
+	back, err := NewAccountFromDto(dto)
+	is.NoErr(err)
+	is.Equal(back.Country, Country("GB"))
+	is.Equal(back.BaseCurrency, Currency("GBP"))
+}
@@ -0,0 +1,149 @@
+package form3_task
+
+import (
+	"context"
+
+	"github.com/petegabriel/form3_task/data"
+)
+
+//ListOptions controls which page of accounts is fetched and how accounts are filtered.
+type ListOptions struct {
+
+	//PageNumber is the zero based index of the page to fetch.
+	PageNumber int
+
+	//PageSize is the number of accounts per page. The api default is used when zero.
+	PageSize int
+
+	//Filter maps an account attribute as named by the api (e.g. 'country',
+	//'bank_id', 'account_number') to the value it must match.
+	Filter map[string]string
+}
+
+//PageLinks holds the links to other pages of a listing. A link is empty when there is no such page.
+type PageLinks struct {
+	First string
+	Last  string
+	Next  string
+	Prev  string
+	Self  string
+}
+
+//AccountPage is a single page of accounts.
+type AccountPage struct {
+
+	//Accounts found in the page.
+	Accounts []*Account
+
+	//Links to navigate to other pages.
+	Links PageLinks
+}
+
+//HasNext reports whether there is a page after this one.
+func (p *AccountPage) HasNext() bool {
+	return p.Links.Next != ""
+}
+
+//ListAccounts retrieves a page of accounts according to the given options.
+//Returns an error if a problem occurs while trying to list the accounts.
+func (c *Client) ListAccounts(opts ListOptions) (*AccountPage, error) {
+	return c.ListAccountsContext(context.Background(), opts)
+}
+
+//ListAccountsContext is like ListAccounts but the request is bound to ctx.
+func (c *Client) ListAccountsContext(ctx context.Context, opts ListOptions) (*AccountPage, error) {
+	found, err := c.gate.List(ctx, opts.params())
+	if err != nil {
+		return nil, c.failed(ctx, "list accounts", "", err)
+	}
+
+	page := &AccountPage{
+		Accounts: make([]*Account, 0, len(found.Data)),
+		Links:    PageLinks(found.Links),
+	}
+	for _, d := range found.Data {
+		acc, err := c.decode(ctx, "list accounts", data.AccountDto{Data: d})
+		if err != nil {
+			return nil, err
+		}
+		page.Accounts = append(page.Accounts, acc)
+	}
+	return page, nil
+}
+
+//IterateAccounts returns an iterator over every account matching the given options,
+//starting at opts.PageNumber. Pages are only fetched when needed.
+func (c *Client) IterateAccounts(ctx context.Context, opts ListOptions) *AccountIterator {
+	return &AccountIterator{
+		client: c,
+		ctx:    ctx,
+		opts:   opts,
+	}
+}
+
+func (o ListOptions) params() data.ListParams {
+	return data.ListParams{
+		PageNumber: o.PageNumber,
+		PageSize:   o.PageSize,
+		Filter:     o.Filter,
+	}
+}
+
+//AccountIterator walks lazily through all the pages of a listing.
+//
+//	it := client.IterateAccounts(ctx, form3_task.ListOptions{PageSize: 100})
+//	for it.Next() {
+//		acc := it.Account()
+//		...
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+type AccountIterator struct {
+	client  *Client
+	ctx     context.Context
+	opts    ListOptions
+	page    *AccountPage
+	pos     int
+	current *Account
+	err     error
+	done    bool
+}
+
+//Next advances the iterator to the next account, fetching a new page if needed.
+//Returns false once there are no more accounts or an error occurred.
+func (it *AccountIterator) Next() bool {
+	for !it.done {
+		if it.page != nil && it.pos < len(it.page.Accounts) {
+			it.current = it.page.Accounts[it.pos]
+			it.pos++
+			return true
+		}
+		if it.page != nil {
+			if !it.page.HasNext() || len(it.page.Accounts) == 0 {
+				break
+			}
+			it.opts.PageNumber++
+		}
+
+		page, err := it.client.ListAccountsContext(it.ctx, it.opts)
+		if err != nil {
+			it.err = err
+			break
+		}
+		it.page, it.pos = page, 0
+	}
+	it.done = true
+	it.current = nil
+	return false
+}
+
+//Account returns the account the iterator currently points to.
+func (it *AccountIterator) Account() *Account {
+	return it.current
+}
+
+//Err returns the error, if any, that stopped the iteration.
+func (it *AccountIterator) Err() error {
+	return it.err
+}
@@ -0,0 +1,106 @@
+package form3_task
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	is2 "github.com/matryer/is"
+	"github.com/petegabriel/form3_task/data"
+)
+
+func TestIterateAccountsWalksAllPages(t *testing.T) {
+	is := is2.New(t)
+	//seven accounts served in pages of three
+	var all []data.Data
+	for i := 0; i < 7; i++ {
+		all = append(all, data.NewAccountDto(getRandomId(), getRandomId(), "GB", []string{"Holder " + strconv.Itoa(i)}).Data)
+	}
+	srv, requests := newPagingServer(all)
+	defer srv.Close()
+
+	client := NewClient(WithBaseURL(srv.URL))
+	it := client.IterateAccounts(context.Background(), ListOptions{PageSize: 3})
+
+	var ids []string
+	for it.Next() {
+		ids = append(ids, it.Account().Id.String())
+	}
+	is.NoErr(it.Err())
+	is.Equal(len(ids), len(all))
+	for i := range all {
+		is.Equal(ids[i], all[i].ID)
+	}
+	is.Equal(*requests, 3)
+	is.Equal(it.Account(), nil)
+}
+
+func TestIterateAccountsIsLazy(t *testing.T) {
+	is := is2.New(t)
+	var all []data.Data
+	for i := 0; i < 4; i++ {
+		all = append(all, data.NewAccountDto(getRandomId(), getRandomId(), "GB", []string{"Holder"}).Data)
+	}
+	srv, requests := newPagingServer(all)
+	defer srv.Close()
+
+	client := NewClient(WithBaseURL(srv.URL))
+	it := client.IterateAccounts(context.Background(), ListOptions{PageSize: 2})
+	is.Equal(*requests, 0)
+	is.True(it.Next())
+	is.True(it.Next())
+	is.Equal(*requests, 1)
+	is.True(it.Next())
+	is.Equal(*requests, 2)
+}
+
+func TestListAccounts(t *testing.T) {
+	is := is2.New(t)
+	all := []data.Data{
+		data.NewAccountDto(getRandomId(), getRandomId(), "GB", []string{"A"}).Data,
+		data.NewAccountDto(getRandomId(), getRandomId(), "GB", []string{"B"}).Data,
+		data.NewAccountDto(getRandomId(), getRandomId(), "GB", []string{"C"}).Data,
+	}
+	srv, _ := newPagingServer(all)
+	defer srv.Close()
+
+	client := NewClient(WithBaseURL(srv.URL))
+	page, err := client.ListAccounts(ListOptions{PageNumber: 1, PageSize: 2})
+
+	is.NoErr(err)
+	is.Equal(len(page.Accounts), 1)
+	is.Equal(page.Accounts[0].Id.String(), all[2].ID)
+	is.True(!page.HasNext())
+	is.True(page.Links.Prev != "")
+}
+
+//newPagingServer serves the given accounts honoring page[number] and page[size]
+//and returns a pointer to the number of requests received.
+func newPagingServer(all []data.Data) (*httptest.Server, *int) {
+	requests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		number, _ := strconv.Atoi(r.URL.Query().Get("page[number]"))
+		size, _ := strconv.Atoi(r.URL.Query().Get("page[size]"))
+		start, end := number*size, (number+1)*size
+		if start > len(all) {
+			start = len(all)
+		}
+		if end > len(all) {
+			end = len(all)
+		}
+
+		page := data.AccountListDto{Data: all[start:end]}
+		if end < len(all) {
+			page.Links.Next = "/v1/organisation/accounts?page[number]=" + strconv.Itoa(number+1)
+		}
+		if number > 0 {
+			page.Links.Prev = "/v1/organisation/accounts?page[number]=" + strconv.Itoa(number-1)
+		}
+		_ = json.NewEncoder(w).Encode(page)
+	}))
+	return srv, &requests
+}
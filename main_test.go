@@ -0,0 +1,22 @@
+package form3_task
+
+import (
+	"os"
+	"testing"
+
+	"github.com/petegabriel/form3_task/fake"
+)
+
+//TestMain runs the tests against the fake account api unless
+//ACCOUNT_API_ADDR points to a real one (e.g. in docker-compose).
+func TestMain(m *testing.M) {
+	if os.Getenv("ACCOUNT_API_ADDR") != "" {
+		os.Exit(m.Run())
+	}
+
+	srv := fake.NewServer()
+	_ = os.Setenv("ACCOUNT_API_ADDR", srv.AccountsURL())
+	code := m.Run()
+	srv.Close()
+	os.Exit(code)
+}
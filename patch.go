@@ -0,0 +1,213 @@
+package form3_task
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/google/uuid"
+	"github.com/petegabriel/form3_task/data"
+)
+
+//AccountPatch lists the attributes of an account to change.
+//Nil fields are not sent and therefore keep their current value.
+//Relationships cannot be changed once the account is created.
+type AccountPatch struct {
+	BaseCurrency            *Currency
+	AccountNumber           *string
+	BankId                  *string
+	BankIdCode              *string
+	Bic                     *string
+	Iban                    *string
+	Name                    []string
+	AlternativeNames        []string
+	Classification          *Classification
+	IsJointAccount          *bool
+	IsAccountMatchingOptOut *bool
+	SecondaryIdentification *string
+	IsSwitched              *bool
+
+	Status                     *AccountStatus
+	StatusReason               *string
+	UserDefinedData            []UserDefinedData
+	ValidationType             *string
+	ReferenceMask              *string
+	AcceptanceQualifier        *string
+	ProcessingService          *string
+	UserDefinedInformation     *string
+	CustomerId                 *string
+	Title                      *string
+	FirstName                  *string
+	BankAccountName            *string
+	PrivateIdentification      *PrivateIdentification
+	OrganisationIdentification *OrganisationIdentification
+}
+
+//NewAccountPatch creates a patch with the attributes that differ from current to updated.
+func NewAccountPatch(current, updated *Account) *AccountPatch {
+	patch := &AccountPatch{}
+	if current.BaseCurrency != updated.BaseCurrency {
+		patch.BaseCurrency = &updated.BaseCurrency
+	}
+	if current.AccountNumber != updated.AccountNumber {
+		patch.AccountNumber = &updated.AccountNumber
+	}
+	if current.BankId != updated.BankId {
+		patch.BankId = &updated.BankId
+	}
+	if current.BankIdCode != updated.BankIdCode {
+		patch.BankIdCode = &updated.BankIdCode
+	}
+	if current.Bic != updated.Bic {
+		patch.Bic = &updated.Bic
+	}
+	if current.Iban != updated.Iban {
+		patch.Iban = &updated.Iban
+	}
+	if !reflect.DeepEqual(current.Name, updated.Name) {
+		patch.Name = updated.Name
+	}
+	if !reflect.DeepEqual(current.AlternativeNames, updated.AlternativeNames) {
+		patch.AlternativeNames = updated.AlternativeNames
+	}
+	if current.Classification != updated.Classification {
+		patch.Classification = &updated.Classification
+	}
+	if current.IsJointAccount != updated.IsJointAccount {
+		patch.IsJointAccount = &updated.IsJointAccount
+	}
+	if current.IsAccountMatchingOptOut != updated.IsAccountMatchingOptOut {
+		patch.IsAccountMatchingOptOut = &updated.IsAccountMatchingOptOut
+	}
+	if current.SecondaryIdentification != updated.SecondaryIdentification {
+		patch.SecondaryIdentification = &updated.SecondaryIdentification
+	}
+	if current.IsSwitched != updated.IsSwitched {
+		patch.IsSwitched = &updated.IsSwitched
+	}
+	if current.Status != updated.Status {
+		patch.Status = &updated.Status
+	}
+	if current.StatusReason != updated.StatusReason {
+		patch.StatusReason = &updated.StatusReason
+	}
+	if !reflect.DeepEqual(current.UserDefinedData, updated.UserDefinedData) {
+		patch.UserDefinedData = updated.UserDefinedData
+	}
+	if current.ValidationType != updated.ValidationType {
+		patch.ValidationType = &updated.ValidationType
+	}
+	if current.ReferenceMask != updated.ReferenceMask {
+		patch.ReferenceMask = &updated.ReferenceMask
+	}
+	if current.AcceptanceQualifier != updated.AcceptanceQualifier {
+		patch.AcceptanceQualifier = &updated.AcceptanceQualifier
+	}
+	if current.ProcessingService != updated.ProcessingService {
+		patch.ProcessingService = &updated.ProcessingService
+	}
+	if current.UserDefinedInformation != updated.UserDefinedInformation {
+		patch.UserDefinedInformation = &updated.UserDefinedInformation
+	}
+	if current.CustomerId != updated.CustomerId {
+		patch.CustomerId = &updated.CustomerId
+	}
+	if current.Title != updated.Title {
+		patch.Title = &updated.Title
+	}
+	if current.FirstName != updated.FirstName {
+		patch.FirstName = &updated.FirstName
+	}
+	if current.BankAccountName != updated.BankAccountName {
+		patch.BankAccountName = &updated.BankAccountName
+	}
+	if !reflect.DeepEqual(current.PrivateIdentification, updated.PrivateIdentification) {
+		patch.PrivateIdentification = updated.PrivateIdentification
+	}
+	if !reflect.DeepEqual(current.OrganisationIdentification, updated.OrganisationIdentification) {
+		patch.OrganisationIdentification = updated.OrganisationIdentification
+	}
+	return patch
+}
+
+//IsEmpty reports whether the patch changes nothing. Empty slices are not sent, so they
+//change nothing either.
+func (p *AccountPatch) IsEmpty() bool {
+	return p.BaseCurrency == nil && p.AccountNumber == nil && p.BankId == nil && p.BankIdCode == nil &&
+		p.Bic == nil && p.Iban == nil && len(p.Name) == 0 && len(p.AlternativeNames) == 0 &&
+		p.Classification == nil && p.IsJointAccount == nil && p.IsAccountMatchingOptOut == nil &&
+		p.SecondaryIdentification == nil && p.IsSwitched == nil &&
+		p.Status == nil && p.StatusReason == nil && len(p.UserDefinedData) == 0 && p.ValidationType == nil &&
+		p.ReferenceMask == nil && p.AcceptanceQualifier == nil && p.ProcessingService == nil &&
+		p.UserDefinedInformation == nil && p.CustomerId == nil && p.Title == nil && p.FirstName == nil &&
+		p.BankAccountName == nil && p.PrivateIdentification == nil && p.OrganisationIdentification == nil
+}
+
+//toDto transforms the patch into the body of the request sent to the account api.
+func (p *AccountPatch) toDto(id uuid.UUID, vrs int) data.AccountPatchDto {
+	attrs := data.AttributesPatch{
+		BaseCurrency:            (*string)(p.BaseCurrency),
+		AccountNumber:           p.AccountNumber,
+		BankID:                  p.BankId,
+		BankIDCode:              p.BankIdCode,
+		Bic:                     p.Bic,
+		Iban:                    p.Iban,
+		Name:                    p.Name,
+		AlternativeNames:        p.AlternativeNames,
+		JointAccount:            p.IsJointAccount,
+		AccountMatchingOptOut:   p.IsAccountMatchingOptOut,
+		SecondaryIdentification: p.SecondaryIdentification,
+		Switched:                p.IsSwitched,
+
+		Status:                     (*string)(p.Status),
+		StatusReason:               p.StatusReason,
+		UserDefinedData:            p.UserDefinedData,
+		ValidationType:             p.ValidationType,
+		ReferenceMask:              p.ReferenceMask,
+		AcceptanceQualifier:        p.AcceptanceQualifier,
+		ProcessingService:          p.ProcessingService,
+		UserDefinedInformation:     p.UserDefinedInformation,
+		CustomerID:                 p.CustomerId,
+		Title:                      p.Title,
+		FirstName:                  p.FirstName,
+		BankAccountName:            p.BankAccountName,
+		PrivateIdentification:      p.PrivateIdentification,
+		OrganisationIdentification: p.OrganisationIdentification,
+	}
+	if p.Classification != nil {
+		cls := string(*p.Classification)
+		attrs.AccountClassification = &cls
+	}
+	return data.AccountPatchDto{
+		Data: data.PatchData{
+			Type:       "accounts",
+			ID:         id.String(),
+			Version:    vrs,
+			Attributes: attrs,
+		},
+	}
+}
+
+//UpdateAccount changes the attributes present in the patch of the account with the given id.
+//The version must match the current version of the account, otherwise an error
+//matching ErrVersionConflict is returned. Returns the account as it is after the change.
+//A nil patch, or one changing nothing, fails with ErrEmptyPatch.
+func (c *Client) UpdateAccount(id string, vrs int, patch *AccountPatch) (*Account, error) {
+	return c.UpdateAccountContext(context.Background(), id, vrs, patch)
+}
+
+//UpdateAccountContext is like UpdateAccount but the request is bound to ctx.
+func (c *Client) UpdateAccountContext(ctx context.Context, id string, vrs int, patch *AccountPatch) (*Account, error) {
+	uid, isUuid := checkUuid(id)
+	if !isUuid {
+		return nil, c.failed(ctx, "update account", id, ErrInvalidID)
+	}
+	if patch == nil || patch.IsEmpty() {
+		return nil, c.failed(ctx, "update account", id, ErrEmptyPatch)
+	}
+
+	updated, err := c.gate.Update(ctx, uid, patch.toDto(uid, vrs))
+	if err != nil {
+		return nil, c.failed(ctx, "update account", id, err)
+	}
+	return c.decode(ctx, "update account", updated)
+}
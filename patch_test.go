@@ -0,0 +1,134 @@
+package form3_task
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync/atomic"
+	"testing"
+
+	is2 "github.com/matryer/is"
+	"github.com/petegabriel/form3_task/data"
+)
+
+func TestNewAccountPatch(t *testing.T) {
+	is := is2.New(t)
+	current := NewAccount([]string{"Samantha Holder"}, "GB", getRandomId(), getRandomId())
+	current.Bic = "NWBKGB22"
+	updated := *current
+	updated.Name = []string{"Samantha", "Holder"}
+	updated.Bic = "NWBKGB42"
+	updated.IsSwitched = true
+
+	patch := NewAccountPatch(current, &updated)
+
+	is.Equal(patch.Name, []string{"Samantha", "Holder"})
+	is.Equal(*patch.Bic, "NWBKGB42")
+	is.Equal(*patch.IsSwitched, true)
+	is.Equal(patch.BankId, nil)
+	is.Equal(patch.Classification, nil)
+	is.True(!patch.IsEmpty())
+	is.True(NewAccountPatch(current, current).IsEmpty())
+}
+
+func TestNewAccountPatchDetailedAttributes(t *testing.T) {
+	is := is2.New(t)
+	current := NewAccount([]string{"Samantha Holder"}, "GB", getRandomId(), getRandomId())
+	current.Status = StatusPending
+	updated := *current
+	updated.Status = StatusConfirmed
+	updated.CustomerId = "cust-42"
+	updated.PrivateIdentification = &PrivateIdentification{BirthDate: "2017-07-23", Country: "GB"}
+
+	patch := NewAccountPatch(current, &updated)
+
+	is.Equal(*patch.Status, StatusConfirmed)
+	is.Equal(*patch.CustomerId, "cust-42")
+	is.Equal(patch.PrivateIdentification, updated.PrivateIdentification)
+	is.Equal(patch.OrganisationIdentification, nil)
+	is.Equal(patch.UserDefinedData, nil)
+	attrs := patch.toDto(getRandomId(), 0).Data.Attributes
+	is.Equal(*attrs.Status, "confirmed")
+	is.Equal(*attrs.CustomerID, "cust-42")
+}
+
+func TestUpdateAccountSendsOnlyChangedAttributes(t *testing.T) {
+	is := is2.New(t)
+	id, orgId := getRandomId(), getRandomId()
+
+	var method string
+	var sent map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method = r.Method
+		_ = json.NewDecoder(r.Body).Decode(&sent)
+		dto := data.NewAccountDto(id, orgId, "GB", []string{"Jane"})
+		dto.Data.Version = 4
+		dto.Data.Attributes.Bic = "NWBKGB42"
+		_ = json.NewEncoder(w).Encode(dto)
+	}))
+	defer srv.Close()
+
+	bic := "NWBKGB42"
+	client := NewClient(WithBaseURL(srv.URL))
+	acc, err := client.UpdateAccount(id.String(), 3, &AccountPatch{Bic: &bic})
+
+	is.NoErr(err)
+	is.Equal(method, http.MethodPatch)
+	body := sent["data"].(map[string]interface{})
+	is.Equal(body["id"], id.String())
+	is.Equal(body["version"], float64(3))
+	is.Equal(body["attributes"], map[string]interface{}{"bic": "NWBKGB42"})
+	is.Equal(acc.Version, 4)
+	is.Equal(acc.Bic, "NWBKGB42")
+}
+
+func TestUpdateAccountVersionConflict(t *testing.T) {
+	is := is2.New(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusConflict)
+		_, _ = w.Write([]byte(`{"error_message":"invalid version"}`))
+	}))
+	defer srv.Close()
+
+	client := NewClient(WithBaseURL(srv.URL))
+	acc, err := client.UpdateAccount(getRandomId().String(), 0, &AccountPatch{Name: []string{"Jane"}})
+
+	is.True(errors.Is(err, ErrVersionConflict))
+	is.True(acc == nil)
+}
+
+func TestUpdateAccountRejectsEmptyPatch(t *testing.T) {
+	is := is2.New(t)
+	var requests int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+	}))
+	defer srv.Close()
+
+	client := NewClient(WithBaseURL(srv.URL))
+	for _, patch := range []*AccountPatch{nil, {}, {Name: []string{}, AlternativeNames: []string{}}} {
+		acc, err := client.UpdateAccount(getRandomId().String(), 0, patch)
+		is.True(errors.Is(err, ErrEmptyPatch))
+		is.True(acc == nil)
+	}
+	is.Equal(atomic.LoadInt32(&requests), int32(0))
+}
+
+func TestAccountPatchIsEmptyChecksEveryField(t *testing.T) {
+	typ := reflect.TypeOf(AccountPatch{})
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		t.Run(field.Name, func(t *testing.T) {
+			patch := &AccountPatch{}
+			value := reflect.ValueOf(patch).Elem().Field(i)
+			if field.Type.Kind() == reflect.Slice {
+				value.Set(reflect.MakeSlice(field.Type, 1, 1))
+			} else {
+				value.Set(reflect.New(field.Type.Elem()))
+			}
+			is2.New(t).True(!patch.IsEmpty())
+		})
+	}
+}
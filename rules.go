@@ -0,0 +1,62 @@
+package form3_task
+
+import "regexp"
+
+//bankRule holds the Form3 rules for the bank details of the accounts of a country.
+type bankRule struct {
+
+	//bankIdCode is the only bank_id_code accepted in the country.
+	bankIdCode string
+
+	//bankId is the format of the bank_id, nil when the country does not support one.
+	bankId *regexp.Regexp
+
+	//bankIdRequired is set when accounts of the country must have a bank_id.
+	bankIdRequired bool
+
+	//bicRequired is set when accounts of the country must have a bic.
+	bicRequired bool
+
+	//accountNumber is the format of the account_number. The api generates one when missing.
+	accountNumber *regexp.Regexp
+
+	//ibanSupported is set when the country uses IBANs. The api generates one when missing.
+	ibanSupported bool
+}
+
+//bankRules lists the countries supported by Form3 and their bank identifier rules.
+//Accounts in other countries are not checked against any rule.
+var bankRules = map[Country]bankRule{
+	"AU": {bankIdCode: "AUBSB", bankId: regexp.MustCompile(`^[0-9]{6}$`), bicRequired: true,
+		accountNumber: regexp.MustCompile(`^[1-9][0-9]{5,9}$`)},
+	"BE": {bankIdCode: "BE", bankId: regexp.MustCompile(`^[0-9]{3}$`), bankIdRequired: true,
+		accountNumber: regexp.MustCompile(`^[0-9]{7}$`), ibanSupported: true},
+	"CA": {bankIdCode: "CACPA", bankId: regexp.MustCompile(`^0[0-9]{8}$`), bicRequired: true,
+		accountNumber: regexp.MustCompile(`^[0-9]{7,12}$`)},
+	"CH": {bankIdCode: "CHBCC", bankId: regexp.MustCompile(`^[0-9]{5}$`), bankIdRequired: true,
+		accountNumber: regexp.MustCompile(`^[0-9A-Z]{12}$`), ibanSupported: true},
+	"DE": {bankIdCode: "DEBLZ", bankId: regexp.MustCompile(`^[0-9]{8}$`), bankIdRequired: true,
+		accountNumber: regexp.MustCompile(`^[0-9]{7}$`), ibanSupported: true},
+	"ES": {bankIdCode: "ESNCC", bankId: regexp.MustCompile(`^[0-9]{8,9}$`), bankIdRequired: true,
+		accountNumber: regexp.MustCompile(`^[0-9]{10}$`), ibanSupported: true},
+	"FR": {bankIdCode: "FR", bankId: regexp.MustCompile(`^[0-9]{10}$`), bankIdRequired: true,
+		accountNumber: regexp.MustCompile(`^[0-9A-Z]{10}$`), ibanSupported: true},
+	"GB": {bankIdCode: "GBDSC", bankId: regexp.MustCompile(`^[0-9]{6}$`), bankIdRequired: true, bicRequired: true,
+		accountNumber: regexp.MustCompile(`^[0-9]{8}$`), ibanSupported: true},
+	"GR": {bankIdCode: "GRBIC", bankId: regexp.MustCompile(`^[0-9]{7}$`), bankIdRequired: true,
+		accountNumber: regexp.MustCompile(`^[0-9A-Z]{16}$`), ibanSupported: true},
+	"HK": {bankIdCode: "HKNCC", bankId: regexp.MustCompile(`^[0-9]{3}$`), bicRequired: true,
+		accountNumber: regexp.MustCompile(`^[0-9]{9,12}$`)},
+	"IT": {bankIdCode: "ITNCC", bankId: regexp.MustCompile(`^[0-9]{10,11}$`), bankIdRequired: true,
+		accountNumber: regexp.MustCompile(`^[0-9A-Z]{12}$`), ibanSupported: true},
+	"LU": {bankIdCode: "LULUX", bankId: regexp.MustCompile(`^[0-9]{3}$`), bankIdRequired: true,
+		accountNumber: regexp.MustCompile(`^[0-9A-Z]{13}$`), ibanSupported: true},
+	"NL": {bicRequired: true,
+		accountNumber: regexp.MustCompile(`^[0-9]{10}$`), ibanSupported: true},
+	"PL": {bankIdCode: "PLKNR", bankId: regexp.MustCompile(`^[0-9]{8}$`), bankIdRequired: true,
+		accountNumber: regexp.MustCompile(`^[0-9]{16}$`), ibanSupported: true},
+	"PT": {bankIdCode: "PTNCC", bankId: regexp.MustCompile(`^[0-9]{8}$`), bankIdRequired: true,
+		accountNumber: regexp.MustCompile(`^[0-9]{11}$`), ibanSupported: true},
+	"US": {bankIdCode: "USABA", bankId: regexp.MustCompile(`^[0-9]{9}$`), bankIdRequired: true, bicRequired: true,
+		accountNumber: regexp.MustCompile(`^[0-9]{6,17}$`)},
+}
@@ -0,0 +1,28 @@
+//go:build go1.21
+// +build go1.21
+
+package form3_task
+
+import (
+	"context"
+	"log/slog"
+)
+
+//slogLogger forwards the events of the client to a *slog.Logger.
+type slogLogger struct {
+	out *slog.Logger
+}
+
+//NewSlogLogger returns a Logger forwarding the events to out, mapping
+//each Level to the slog level of the same name.
+func NewSlogLogger(out *slog.Logger) Logger {
+	return slogLogger{out: out}
+}
+
+func (l slogLogger) Log(ctx context.Context, level Level, msg string, fields ...Field) {
+	attrs := make([]slog.Attr, 0, len(fields))
+	for _, f := range fields {
+		attrs = append(attrs, slog.Any(f.Key, f.Value))
+	}
+	l.out.LogAttrs(ctx, slog.Level(level*4), msg, attrs...)
+}
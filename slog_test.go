@@ -0,0 +1,34 @@
+//go:build go1.21
+// +build go1.21
+
+package form3_task
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+
+	is2 "github.com/matryer/is"
+)
+
+func TestSlogLogger(t *testing.T) {
+	is := is2.New(t)
+	var buf bytes.Buffer
+	handler := slog.NewTextHandler(&buf, &slog.HandlerOptions{
+		Level: slog.LevelInfo,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	})
+	logger := NewSlogLogger(slog.New(handler))
+
+	logger.Log(context.Background(), LevelDebug, "sending request")
+	logger.Log(context.Background(), LevelWarn, "retrying request", Field{Key: "status", Value: 503})
+	logger.Log(context.Background(), LevelError, "get account failed", Field{Key: "account_id", Value: "abc"})
+
+	is.Equal(buf.String(), "level=WARN msg=\"retrying request\" status=503\nlevel=ERROR msg=\"get account failed\" account_id=abc\n")
+}
@@ -0,0 +1,147 @@
+package form3_task
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/petegabriel/form3_task/internal/iso"
+)
+
+//Maximum sizes of the account fields accepted by the account api.
+const (
+	maxNameLines       = 4
+	maxAltNames        = 3
+	maxLineLength      = 140
+	maxSecondaryIdSize = 140
+)
+
+var bicPattern = regexp.MustCompile(`^[A-Z]{6}[A-Z0-9]{2}([A-Z0-9]{3})?$`)
+
+//ValidationError lists every problem found in an account. It matches ErrValidation.
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, 0, len(e.Fields))
+	for _, f := range e.Fields {
+		msgs = append(msgs, f.Error())
+	}
+	return fmt.Sprintf("%s: %s", ErrValidation, strings.Join(msgs, "; "))
+}
+
+//Is reports whether target is ErrValidation.
+func (e *ValidationError) Is(target error) bool {
+	return target == ErrValidation
+}
+
+//Validate checks the account against the rules of the account api before
+//it is sent. Returns a *ValidationError listing every problem found, or nil.
+func (info *Account) Validate() error {
+	v := &validator{}
+
+	if info.Id == uuid.Nil {
+		v.add("id", "required", "is required")
+	}
+	if info.OrganisationId == uuid.Nil {
+		v.add("organisation_id", "required", "is required")
+	}
+
+	if info.Country == "" {
+		v.add("country", "required", "is required")
+	} else if !info.Country.IsValid() {
+		v.add("country", "iso3166", fmt.Sprintf("%q is not an ISO 3166-1 alpha-2 country code", info.Country))
+	}
+	if info.BaseCurrency != "" && !info.BaseCurrency.IsValid() {
+		v.add("base_currency", "iso4217", fmt.Sprintf("%q is not an ISO 4217 currency code", info.BaseCurrency))
+	}
+	if info.Bic != "" && (!bicPattern.MatchString(info.Bic) || !iso.IsCountry(info.Bic[4:6])) {
+		v.add("bic", "format", fmt.Sprintf("%q is not a BIC in either 8 or 11 characters format", info.Bic))
+	}
+
+	if len(info.Name) == 0 {
+		v.add("name", "required", "is required")
+	} else if len(info.Name) > maxNameLines {
+		v.add("name", "max_items", fmt.Sprintf("should have at most %d lines", maxNameLines))
+	}
+	v.lines("name", info.Name)
+	if len(info.AlternativeNames) > maxAltNames {
+		v.add("alternative_names", "max_items", fmt.Sprintf("should have at most %d names", maxAltNames))
+	}
+	v.lines("alternative_names", info.AlternativeNames)
+
+	switch info.Classification {
+	case "", Personal, Business:
+	default:
+		v.add("account_classification", "enum", fmt.Sprintf("should be one of [%s %s]", Personal, Business))
+	}
+	switch info.Status {
+	case "", StatusPending, StatusConfirmed, StatusFailed, StatusClosed:
+	default:
+		v.add("status", "enum", fmt.Sprintf("should be one of [%s %s %s %s]", StatusPending, StatusConfirmed, StatusFailed, StatusClosed))
+	}
+	if len(info.SecondaryIdentification) > maxSecondaryIdSize {
+		v.add("secondary_identification", "max_length", fmt.Sprintf("should be at most %d chars long", maxSecondaryIdSize))
+	}
+
+	v.bank(info)
+	return v.err()
+}
+
+//validator collects the problems found while validating an account.
+type validator struct {
+	fields []FieldError
+}
+
+func (v *validator) add(field, rule, msg string) {
+	v.fields = append(v.fields, FieldError{Field: field, Rule: rule, Message: msg})
+}
+
+func (v *validator) lines(field string, lines []string) {
+	for i, line := range lines {
+		path := fmt.Sprintf("%s.%d", field, i)
+		if strings.TrimSpace(line) == "" {
+			v.add(path, "required", "should not be empty")
+		} else if len(line) > maxLineLength {
+			v.add(path, "max_length", fmt.Sprintf("should be at most %d chars long", maxLineLength))
+		}
+	}
+}
+
+//bank checks the bank identifier, account number and iban against the rules of the account's country.
+func (v *validator) bank(info *Account) {
+	rule, found := bankRules[info.Country]
+	if !found {
+		return
+	}
+	if info.BankIdCode != "" && info.BankIdCode != rule.bankIdCode {
+		if rule.bankIdCode == "" {
+			v.add("bank_id_code", "not_supported", fmt.Sprintf("is not supported in %s", info.Country))
+		} else {
+			v.add("bank_id_code", "format", fmt.Sprintf("should be %s in %s", rule.bankIdCode, info.Country))
+		}
+	}
+	if info.AccountNumber != "" && rule.accountNumber != nil && !rule.accountNumber.MatchString(info.AccountNumber) {
+		v.add("account_number", "format", fmt.Sprintf("should match '%s' in %s", rule.accountNumber, info.Country))
+	}
+	if info.Iban != "" && !rule.ibanSupported {
+		v.add("iban", "not_supported", fmt.Sprintf("is not supported in %s", info.Country))
+	}
+	if info.BankId == "" {
+		return
+	}
+	if rule.bankId == nil {
+		v.add("bank_id", "not_supported", fmt.Sprintf("is not supported in %s", info.Country))
+	} else if !rule.bankId.MatchString(info.BankId) {
+		v.add("bank_id", "format", fmt.Sprintf("should match '%s' in %s", rule.bankId, info.Country))
+	}
+}
+
+func (v *validator) err() error {
+	if len(v.fields) == 0 {
+		return nil
+	}
+	return &ValidationError{Fields: v.fields}
+}
@@ -0,0 +1,94 @@
+package form3_task
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	is2 "github.com/matryer/is"
+)
+
+func TestValidateValidAccounts(t *testing.T) {
+	is := is2.New(t)
+	full := NewAccount([]string{"Samantha Holder"}, "GB", getRandomId(), getRandomId())
+	full.BaseCurrency = "GBP"
+	full.BankId = "400300"
+	full.BankIdCode = "GBDSC"
+	full.Bic = "NWBKGB22"
+	full.AlternativeNames = []string{"Sam Holder"}
+
+	is.NoErr(full.Validate())
+	is.NoErr(NewAccount([]string{"Pedro", "Almeida"}, "PT", getRandomId(), getRandomId()).Validate())
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(*Account)
+		field  string
+		rule   string
+	}{
+		{"missing id", func(a *Account) { a.Id = uuid.Nil }, "id", "required"},
+		{"missing organisation", func(a *Account) { a.OrganisationId = uuid.Nil }, "organisation_id", "required"},
+		{"missing country", func(a *Account) { a.Country = "" }, "country", "required"},
+		{"unknown country", func(a *Account) { a.Country = "UK" }, "country", "iso3166"},
+		{"lower case country", func(a *Account) { a.Country = "gb" }, "country", "iso3166"},
+		{"unknown currency", func(a *Account) { a.BaseCurrency = "GBX" }, "base_currency", "iso4217"},
+		{"unknown status", func(a *Account) { a.Status = "open" }, "status", "enum"},
+		{"short bic", func(a *Account) { a.Bic = "NWBKGB2" }, "bic", "format"},
+		{"bic of unknown country", func(a *Account) { a.Bic = "NWBKZZ22" }, "bic", "format"},
+		{"bic with 10 chars", func(a *Account) { a.Bic = "NWBKGB22XX" }, "bic", "format"},
+		{"no name", func(a *Account) { a.Name = nil }, "name", "required"},
+		{"five name lines", func(a *Account) { a.Name = []string{"a", "b", "c", "d", "e"} }, "name", "max_items"},
+		{"blank name line", func(a *Account) { a.Name = []string{"Jane", " "} }, "name.1", "required"},
+		{"long name line", func(a *Account) { a.Name = []string{strings.Repeat("x", 141)} }, "name.0", "max_length"},
+		{"four alternative names", func(a *Account) { a.AlternativeNames = []string{"a", "b", "c", "d"} }, "alternative_names", "max_items"},
+		{"unknown classification", func(a *Account) { a.Classification = "Joint" }, "account_classification", "enum"},
+		{"wrong bank id code", func(a *Account) { a.BankIdCode = "DEBLZ" }, "bank_id_code", "format"},
+		{"wrong bank id", func(a *Account) { a.BankId = "40030" }, "bank_id", "format"},
+		{"bank id not supported", func(a *Account) { a.Country, a.BankId = "NL", "1234" }, "bank_id", "not_supported"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			is := is2.New(t)
+			acc := NewAccount([]string{"Jane Doe"}, "GB", getRandomId(), getRandomId())
+			tt.change(acc)
+
+			err := acc.Validate()
+
+			var valErr *ValidationError
+			is.True(errors.As(err, &valErr))
+			is.True(errors.Is(err, ErrValidation))
+			is.Equal(len(valErr.Fields), 1)
+			is.Equal(valErr.Fields[0].Field, tt.field)
+			is.Equal(valErr.Fields[0].Rule, tt.rule)
+		})
+	}
+}
+
+func TestValidateReportsEveryProblem(t *testing.T) {
+	is := is2.New(t)
+	acc := NewAccount([]string{"", "Doe"}, "XX", getRandomId(), uuid.Nil)
+	acc.BaseCurrency = "EU"
+
+	err := acc.Validate()
+
+	var valErr *ValidationError
+	is.True(errors.As(err, &valErr))
+	is.Equal(len(valErr.Fields), 4)
+	is.Equal(err.Error(), `account data is not valid: organisation_id is required; country "XX" is not an ISO 3166-1 alpha-2 country code; base_currency "EU" is not an ISO 4217 currency code; name.0 should not be empty`)
+}
+
+func TestCreateAccountValidatesBeforeSending(t *testing.T) {
+	is := is2.New(t)
+	gate := &stubGateway{}
+	client := NewClient(WithGateway(gate))
+	acc := NewAccount([]string{"Jane"}, "UK", getRandomId(), getRandomId())
+
+	created, err := client.CreateAccount(acc)
+
+	is.True(errors.Is(err, ErrValidation))
+	is.True(created == nil)
+	is.Equal(gate.createCalls, 0)
+}